@@ -62,7 +62,8 @@ func Example2() {
 	sales := NewTable(salesFlds, NotShared, "sales")
 	sales.LoadSome(salesIds)
 	sales.CreateOrderBy("byAmt", "amt:d") // sort by amt descending
-	sales.Loop(showSale, "byAmt")
+	// join each sale to its customer, customers rec key is sale's custId
+	sales.Join(customers, "custId", InnerJoin).Loop(showSale, "byAmt")
 
 	// Output:
 	// ....... Example2 ..........
@@ -71,11 +72,10 @@ func Example2() {
 }
 
 // display info for a sale, including name of related customer
-func showSale(key string, salesRec *Rec) {
-	customer := customers.GetRec(salesRec.Get("custId"))
-	saleDate := salesRec.GetDate("date")
+func showSale(key string, sale *JoinRec) {
+	saleDate := sale.GetDate("date")
 	line := "Customer: %s -- Amt: %.2f Date: %s\n"
-	fmt.Printf(line, customer.Get("name"), salesRec.GetFloat("amt"), saleDate.Format("Jan 02, 2006"))
+	fmt.Printf(line, sale.Get("customers.name"), sale.GetFloat("amt"), saleDate.Format("Jan 02, 2006"))
 }
 
 // load some customer data into database
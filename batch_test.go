@@ -0,0 +1,58 @@
+// These tests save recs from many goroutines using SaveBatched.
+
+package bo
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSaveBatched(t *testing.T) {
+	CreateBucket("visits")
+	visitFlds := FldMap{"page": "str", "hits": "int"}
+	keys := NewTable(visitFlds, NotShared, "visits").GetNextKeys(50)
+
+	var wait sync.WaitGroup
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		wait.Add(1)
+		go func(i int, key string) { // simulates a web handler saving a single rec
+			defer wait.Done()
+			tbl := NewTable(visitFlds, NotShared, "visits")
+			tbl.CreateRecMap()
+			tbl.AddRec(key, ValMap{"page": "home", "hits": IntToStr(int64(i))})
+			_, errs[i] = tbl.SaveBatched()
+			if tbl.GetRec(key).Vals["#c"] == "1" {
+				t.Error("rec still marked as changed after SaveBatched, key: ", key)
+			}
+		}(i, key)
+	}
+	wait.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatal("SaveBatched failed, key: ", keys[i], " ", err)
+		}
+	}
+	tbl := NewTable(visitFlds, NotShared, "visits")
+	if count := tbl.Load(); count != len(keys) {
+		t.Fatal("SaveBatched rec count wrong: ", count)
+	}
+
+	tbl.DeleteRec(keys[0])
+	if count, err := tbl.SaveBatched(); count != 1 || err != nil {
+		t.Fatal("SaveBatched delete failed: ", count, err)
+	}
+	if tbl.GetRec(keys[0]) != nil {
+		t.Fatal("deleted rec not removed from RecMap")
+	}
+
+	badTbl := NewTable(visitFlds, NotShared, "noSuchBkt")
+	badTbl.CreateRecMap()
+	badTbl.AddRec("0001")
+	if _, err := badTbl.SaveBatched(); err == nil {
+		t.Fatal("SaveBatched to missing bucket did not return error")
+	}
+	if badTbl.GetRec("0001").Vals["#c"] != "1" {
+		t.Fatal("failed SaveBatched changed RecMap")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/boltdb/bolt"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -38,12 +39,28 @@ func CommitDBWrite(tx *bolt.Tx) {
 	}
 }
 
+// Batch calls fn inside a write transaction shared with other goroutines calling Batch
+// at about the same time (see bolt's DB.Batch). Each caller receives its own error.
+// If the shared transaction fails, fn may be called again, so it must not change app state.
+// Only use tx in fn (ex. bucket Get, Put), not Table.Save, which changes RecMap.
+// To save Tables this way, use Table.SaveBatched. A panic inside fn is returned as an error.
+func Batch(fn func(tx *bolt.Tx) error) error {
+	return db.Batch(func(tx *bolt.Tx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("bo.Batch: %v", r)
+			}
+		}()
+		return fn(tx)
+	})
+}
+
 // --- types & methods for sorting --------------------------------------------------------
 
 type sortVal struct {
-	direction string // asc or desc
-	val       interface{}
-	valType   string
+	spec    *sortSpec
+	val     interface{} // int64, float64, bool or string based on spec.valType
+	missing bool        // rec has no value for fld
 }
 type sortRec struct {
 	recKey string
@@ -58,53 +75,43 @@ func (this sortRecs) Swap(a, b int) {
 	this[a], this[b] = this[b], this[a]
 }
 func (this sortRecs) Less(a, b int) bool {
-	var trueResult, falseResult bool
-	result := 0
-	for i, vala := range this[a].vals {
-		if vala.direction == "desc" { // if descending, reverse response
-			trueResult = false
-			falseResult = true
-		} else {
-			trueResult = true
-			falseResult = false
+	return compareWithKey(this[a], this[b]) < 0
+}
+
+// compareSortRecs returns -1 if a sorts before b, 1 if a sorts after b, 0 if equal.
+// Only sort values are compared, see compareWithKey.
+func compareSortRecs(a, b *sortRec) int {
+	for i := range a.vals {
+		vala, valb := &a.vals[i], &b.vals[i]
+		spec := vala.spec
+		if vala.missing && valb.missing {
+			continue
 		}
-		switch vala.valType {
-		case "int":
-			inta := vala.val.(int64)
-			intb := this[b].vals[i].val.(int64)
-			if inta < intb {
-				result = -1
-			} else if inta > intb {
-				result = 1
-			}
-		case "float":
-			floata := vala.val.(float64)
-			floatb := this[b].vals[i].val.(float64)
-			if floata < floatb {
-				result = -1
-			} else if floata > floatb {
-				result = 1
-			}
-		// case "date" works using default string
-		default:
-			stra := vala.val.(string)
-			strb := this[b].vals[i].val.(string)
-			if stra < strb {
-				result = -1
-			} else if stra > strb {
-				result = 1
+		if spec.missing != "" && vala.missing != valb.missing { // missing first/last ignores direction
+			if vala.missing == (spec.missing == "first") {
+				return -1
 			}
+			return 1
 		}
+		result := spec.compare(vala.val, valb.val)
 		if result == 0 {
 			continue
 		}
-		break
+		if spec.desc { // if descending, reverse response
+			return -result
+		}
+		return result
 	}
-	if result < 0 { // -1 indicates a < b
-		return trueResult
-	} else {
-		return falseResult
+	return 0
+}
+
+// compareWithKey compares sort values, then rec keys, so sorting is stable and every rec
+// has a unique position.
+func compareWithKey(a, b *sortRec) int {
+	if result := compareSortRecs(a, b); result != 0 {
+		return result
 	}
+	return strings.Compare(a.recKey, b.recKey)
 }
 
 // ------------------------------
@@ -121,7 +128,8 @@ func OpenBucket(tx *bolt.Tx, bktPath []string) *bolt.Bucket {
 	return bkt
 }
 
-func BucketExists(bktPath []string) bool {
+// BucketExists returns true if bucket bktPath exists.
+func BucketExists(bktPath ...string) bool {
 	var bktExists bool
 	db.View(func(tx *bolt.Tx) error {
 		bkt := tx.Bucket(bs(bktPath[0]))
@@ -233,7 +233,7 @@ func Benchmark_Unmarshal(b *testing.B) {
 		"f4": "field number 4",
 		"f5": "field number 5",
 	}
-	rec1 := Rec{nil, valMap}
+	rec1 := Rec{Tbl: nil, Vals: valMap}
 	jsonBytes := rec1.Vals.toJson()
 	rec2 := Rec{Vals: make(ValMap)}
 	for n := 0; n < b.N; n++ {
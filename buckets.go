@@ -0,0 +1,193 @@
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"log"
+)
+
+// bucketParent is a *bolt.Tx (root level) or *bolt.Bucket.
+type bucketParent interface {
+	Bucket(name []byte) *bolt.Bucket
+	CreateBucket(name []byte) (*bolt.Bucket, error)
+	DeleteBucket(name []byte) error
+}
+
+// openParent returns the bucket containing the last bucket in bktPath (tx if bktPath has 1 name).
+// If create is true, missing buckets are created, else nil is returned if any are missing.
+func openParent(tx *bolt.Tx, bktPath []string, create bool) bucketParent {
+	if len(bktPath) == 0 {
+		log.Panic("bucket path required")
+	}
+	var parent bucketParent = tx
+	for _, name := range bktPath[:len(bktPath)-1] {
+		bkt := parent.Bucket(bs(name))
+		if bkt == nil && create {
+			var err error
+			if bkt, err = parent.CreateBucket(bs(name)); err != nil {
+				return nil
+			}
+		}
+		if bkt == nil {
+			return nil
+		}
+		parent = bkt
+	}
+	return parent
+}
+
+// CreateBucketIfNotExists creates bucket bktPath and any missing buckets above it.
+// Does nothing if the bucket already exists.
+func CreateBucketIfNotExists(bktPath ...string) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		parent := openParent(tx, bktPath, true)
+		if parent == nil {
+			log.Panic("CreateBucketIfNotExists failed, path contains a key: ", bktPath)
+		}
+		if parent.Bucket(bs(bktPath[len(bktPath)-1])) != nil {
+			return nil
+		}
+		_, err := parent.CreateBucket(bs(bktPath[len(bktPath)-1]))
+		return err
+	})
+	if err != nil {
+		log.Panic("CreateBucketIfNotExists failed, ", err, bktPath)
+	}
+}
+
+// DeleteBucket deletes bucket bktPath, its recs and nested buckets.
+// Schemas registered for them are also deleted.
+func DeleteBucket(bktPath ...string) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		parent := openParent(tx, bktPath, false)
+		if parent == nil || parent.Bucket(bs(bktPath[len(bktPath)-1])) == nil {
+			return bolt.ErrBucketNotFound
+		}
+		if err := deleteSchemas(tx, bktPath); err != nil {
+			return err
+		}
+		return parent.DeleteBucket(bs(bktPath[len(bktPath)-1]))
+	})
+	if err != nil {
+		log.Panic("DeleteBucket failed, ", err, bktPath)
+	}
+}
+
+// copyBucket copies recs, nested buckets and sequence of src into a new bucket name in dst.
+func copyBucket(src *bolt.Bucket, dst bucketParent, name []byte) error {
+	bkt, err := dst.CreateBucket(name)
+	if err != nil {
+		return err
+	}
+	err = src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			return copyBucket(src.Bucket(k), bkt, k)
+		}
+		return bkt.Put(k, v)
+	})
+	if err != nil {
+		return err
+	}
+	return bkt.SetSequence(src.Sequence())
+}
+
+// moveBucket copies bucket from to path to in tx, deleting from if move is true.
+// Missing buckets above to are created, to must not exist.
+func moveBucket(tx *bolt.Tx, from, to []string, move bool) error {
+	if len(to) >= len(from) && samePath(from, to[:len(from)]) {
+		log.Panic("cannot copy or move bucket into itself, from: ", from, ", to: ", to)
+	}
+	src := OpenBucket(tx, from)
+	dst := openParent(tx, to, true)
+	if dst == nil {
+		return bolt.ErrIncompatibleValue
+	}
+	if err := copyBucket(src, dst, bs(to[len(to)-1])); err != nil {
+		return err
+	}
+	if err := moveSchemas(tx, from, to, move); err != nil {
+		return err
+	}
+	if !move {
+		return nil
+	}
+	return openParent(tx, from, false).DeleteBucket(bs(from[len(from)-1]))
+}
+
+// CopyBucket copies bucket from, including nested buckets, sequence numbers and registered schemas,
+// to a new bucket.
+// Buckets above to are created if missing. Panics if to exists.
+func CopyBucket(from, to []string) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		return moveBucket(tx, from, to, false)
+	}); err != nil {
+		log.Panic("CopyBucket failed, ", err, from, to)
+	}
+}
+
+// MoveBucket moves bucket from to a new path, in 1 transaction. See CopyBucket.
+// Schemas registered for from and its nested buckets are moved to the new path (see CopyBucket).
+func MoveBucket(from, to []string) {
+	if err := db.Update(func(tx *bolt.Tx) error {
+		return moveBucket(tx, from, to, true)
+	}); err != nil {
+		log.Panic("MoveBucket failed, ", err, from, to)
+	}
+}
+
+// RenameBucket changes the name of the last bucket in bktPath to newName.
+// Bolt cannot rename buckets, the bucket is copied and the original deleted (1 transaction).
+// Registered schemas are moved, as in MoveBucket.
+func RenameBucket(bktPath []string, newName string) {
+	to := append(append([]string(nil), bktPath[:len(bktPath)-1]...), newName)
+	if err := db.Update(func(tx *bolt.Tx) error {
+		return moveBucket(tx, bktPath, to, true)
+	}); err != nil {
+		log.Panic("RenameBucket failed, ", err, bktPath, newName)
+	}
+}
+
+// BucketInfo describes a bucket returned by ListBuckets.
+type BucketInfo struct {
+	Name    string
+	Recs    int // number of recs (not including nested buckets)
+	Buckets int // number of nested buckets
+}
+
+// ListBuckets returns the buckets inside bktPath (root level buckets if no bktPath), in name order.
+// Metadata buckets (see MetaBktName) are not included.
+func ListBuckets(bktPath ...string) []BucketInfo {
+	list := make([]BucketInfo, 0)
+	db.View(func(tx *bolt.Tx) error {
+		add := func(name []byte, bkt *bolt.Bucket) {
+			if isMetaBkt(name) {
+				return
+			}
+			info := BucketInfo{Name: string(name)}
+			bkt.ForEach(func(k, v []byte) error {
+				if v == nil {
+					if !isMetaBkt(k) {
+						info.Buckets++
+					}
+				} else {
+					info.Recs++
+				}
+				return nil
+			})
+			list = append(list, info)
+		}
+		if len(bktPath) == 0 {
+			return tx.ForEach(func(name []byte, bkt *bolt.Bucket) error {
+				add(name, bkt)
+				return nil
+			})
+		}
+		bkt := OpenBucket(tx, bktPath)
+		return bkt.ForEach(func(k, v []byte) error {
+			if v == nil {
+				add(k, bkt.Bucket(k))
+			}
+			return nil
+		})
+	})
+	return list
+}
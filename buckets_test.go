@@ -0,0 +1,77 @@
+// These tests check bucket management funcs, using buckets under "archive".
+
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"testing"
+)
+
+func TestBucketManagement(t *testing.T) {
+	CreateBucketIfNotExists("archive", "2023", "notes")
+	CreateBucketIfNotExists("archive", "2023", "notes") // exists, does nothing
+	if !BucketExists("archive", "2023", "notes") || BucketExists("archive", "2024") {
+		t.Fatal("CreateBucketIfNotExists or BucketExists wrong")
+	}
+	notes := NewTable(FldMap{"text": "str"}, NotShared, "archive", "2023")
+	notes.CreateRecMap()
+	notes.AddRec("n1", ValMap{"text": "a"})
+	notes.AddRec("n2", ValMap{"text": "b"})
+	tx := StartDBWrite()
+	notes.Save(tx)
+	CommitDBWrite(tx)
+	notes.GetNextKeys(5) // sequence is copied
+	RegisterSchema(FldMap{"line": "int"}, "archive", "2023", "notes")
+
+	CopyBucket([]string{"archive", "2023"}, []string{"archive", "copies", "2023"})
+	if GetSchema("archive", "copies", "2023", "notes")["line"] != "int" || GetSchema("archive", "2023", "notes") == nil {
+		t.Fatal("CopyBucket schema wrong")
+	}
+	list := ListBuckets("archive", "copies")
+	if len(list) != 1 || list[0].Name != "2023" || list[0].Recs != 2 || list[0].Buckets != 1 {
+		t.Fatal("CopyBucket or ListBuckets wrong: ", list)
+	}
+	copies := NewTable(FldMap{"text": "str"}, NotShared, "archive", "copies", "2023")
+	if copies.Load1("n2") != 1 || copies.GetNextKey() != "00000006" {
+		t.Fatal("copied recs or sequence wrong")
+	}
+
+	RegisterSchema(FldMap{"text": "str"}, "archive", "copies", "2023")
+	RegisterSchema(FldMap{"line": "int"}, "archive", "copies", "2023", "notes")
+	RegisterSchema(FldMap{"text": "str"}, "archive", "copies", "2023x") // not nested in 2023
+	RenameBucket([]string{"archive", "copies", "2023"}, "old")
+	MoveBucket([]string{"archive", "copies", "old"}, []string{"attic", "old"})
+	if BucketExists("archive", "copies", "2023") || BucketExists("archive", "copies", "old") || !BucketExists("attic", "old", "notes") {
+		t.Fatal("RenameBucket or MoveBucket wrong")
+	}
+	if GetSchema("attic", "old")["text"] != "str" || GetSchema("attic", "old", "notes")["line"] != "int" {
+		t.Fatal("schemas not moved")
+	}
+	if GetSchema("archive", "copies", "2023") != nil || GetSchema("archive", "copies", "old") != nil || GetSchema("archive", "copies", "2023x") == nil {
+		t.Fatal("moved schemas wrong at old path")
+	}
+	db.View(func(tx *bolt.Tx) error {
+		if OpenBucket(tx, []string{"attic", "old"}).Get(bs("n2")) == nil {
+			t.Error("moved recs missing")
+		}
+		return nil
+	})
+
+	DeleteBucket("attic")
+	if BucketExists("attic") {
+		t.Fatal("DeleteBucket failed")
+	}
+	CreateBucketIfNotExists("attic", "old", "notes")
+	if GetSchema("attic", "old") != nil || GetSchema("attic", "old", "notes") != nil {
+		t.Fatal("DeleteBucket left schemas")
+	}
+	DeleteBucket("attic")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("move into itself did not panic")
+			}
+		}()
+		MoveBucket([]string{"archive"}, []string{"archive", "inner"})
+	}()
+}
@@ -0,0 +1,79 @@
+// These tests check field level change tracking and merged saves.
+
+package bo
+
+import (
+	"testing"
+)
+
+var memberFlds = FldMap{
+	"name":  "str",
+	"email": "str",
+	"hits":  "int",
+}
+
+func TestChangedFields(t *testing.T) {
+	CreateBucket("members")
+	members := NewTable(memberFlds, NotShared, "members")
+	members.CreateRecMap()
+	rec := members.AddRec("0001", ValMap{"name": "Ann", "hits": "1"})
+	if flds := rec.ChangedFields(); len(flds) != 2 || flds[0] != "hits" || flds[1] != "name" {
+		t.Fatal("added rec changed flds wrong: ", flds)
+	}
+	tx := StartDBWrite()
+	members.Save(tx)
+	CommitDBWrite(tx)
+	if flds := rec.ChangedFields(); len(flds) != 0 {
+		t.Fatal("changed flds not cleared by Save: ", flds)
+	}
+
+	members.Load()
+	rec = members.GetRec("0001")
+	rec.Set("name", "Anne")
+	rec.Set("email", "anne@x.com")
+	rec.SetInt("hits", 1) // same as original value
+	if flds := rec.ChangedFields(); len(flds) != 2 || flds[0] != "email" || flds[1] != "name" {
+		t.Fatal("changed flds wrong: ", flds)
+	}
+	if orig := rec.Original("name"); orig != "Ann" {
+		t.Fatal("Original name wrong: ", orig)
+	}
+	rec.Revert("name")
+	rec.Revert("email")
+	if rec.Get("name") != "Ann" || rec.Get("email", "none") != "none" {
+		t.Fatal("Revert failed: ", rec.Vals)
+	}
+	if rec.Vals["#c"] == "1" {
+		t.Fatal("change flag still on after all flds reverted")
+	}
+}
+
+func TestMergeSave(t *testing.T) {
+	// 2 tables simulate 2 processes editing different flds of the same rec
+	tbl1 := NewTable(memberFlds, NotShared, "members")
+	tbl1.MergeSave = true
+	tbl1.Load1("0001")
+	tbl2 := NewTable(memberFlds, NotShared, "members")
+	tbl2.MergeSave = true
+	tbl2.Load1("0001")
+
+	tbl1.GetRec("0001").Set("email", "ann@x.com")
+	tbl2.GetRec("0001").SetInt("hits", 2)
+
+	tx := StartDBWrite()
+	tbl1.Save(tx)
+	CommitDBWrite(tx)
+	tx = StartDBWrite()
+	tbl2.Save(tx)
+	CommitDBWrite(tx)
+
+	if rec := tbl2.GetRec("0001"); rec.Get("email") != "ann@x.com" {
+		t.Fatal("merged values not in table after save: ", rec.Vals)
+	}
+	tbl := NewTable(memberFlds, NotShared, "members")
+	tbl.Load1("0001")
+	rec := tbl.GetRec("0001")
+	if rec.Get("name") != "Ann" || rec.Get("email") != "ann@x.com" || rec.GetInt("hits") != 2 {
+		t.Fatal("merge save failed: ", rec.Vals)
+	}
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/txjmp/bo"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// jsonRec is the JSON form of a rec.
+type jsonRec struct {
+	Key  string    `json:"key"`
+	Vals bo.ValMap `json:"vals"`
+}
+
+// parsePath splits a "/" separated bucket path, "" or "/" is the root.
+func parsePath(s string) []string {
+	s = strings.Trim(s, "/")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "/")
+}
+
+// bucketPath returns the bucket path in args[0], returns error if missing or bucket does not exist.
+func bucketPath(args []string) ([]string, error) {
+	if len(args) == 0 || len(parsePath(args[0])) == 0 {
+		return nil, fmt.Errorf("bucket path required")
+	}
+	path := parsePath(args[0])
+	if !bo.BucketExists(path...) {
+		return nil, fmt.Errorf("bucket not found: %s", args[0])
+	}
+	return path, nil
+}
+
+// selectFlags parses --prefix, --range, --limit, --reverse in args.
+func selectFlags(cmd string, args []string) (bo.ScanOpts, int, error) {
+	var opts bo.ScanOpts
+	var keyRange string
+	var limit int
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.StringVar(&opts.Prefix, "prefix", "", "keys beginning with prefix")
+	flags.StringVar(&keyRange, "range", "", "keys from start to end (inclusive), start..end")
+	if cmd == "scan" {
+		flags.IntVar(&limit, "limit", 0, "maximum recs, 0 = all")
+		flags.BoolVar(&opts.Reverse, "reverse", false, "descending key order")
+	}
+	if err := flags.Parse(args); err != nil {
+		return opts, 0, err
+	}
+	if flags.NArg() > 0 {
+		return opts, 0, fmt.Errorf("unexpected argument: %s", flags.Arg(0))
+	}
+	if keyRange != "" {
+		x := strings.Index(keyRange, "..")
+		if x == -1 {
+			return opts, 0, fmt.Errorf("--range must be start..end")
+		}
+		opts.Start, opts.End = keyRange[:x], keyRange[x+2:]
+	}
+	return opts, limit, nil
+}
+
+// recs calls fn for each rec in path selected by opts (nested buckets are skipped), up to limit recs.
+func recs(path []string, opts bo.ScanOpts, limit int, fn func(key string, vals bo.ValMap)) int {
+	tbl := bo.NewTable(bo.FldMap{}, bo.NotShared, path...)
+	iter := tbl.Iterator(opts)
+	defer iter.Close()
+	var count int
+	for iter.Next() {
+		if iter.IsBucket() {
+			continue
+		}
+		fn(iter.Key(), iter.Rec().Vals)
+		count++
+		if count == limit {
+			break
+		}
+	}
+	return count
+}
+
+// sortedFlds returns fld names in vals (without Bo's "#" flds), in order.
+func sortedFlds(vals bo.ValMap) []string {
+	flds := make([]string, 0, len(vals))
+	for fld := range vals {
+		if !strings.HasPrefix(fld, "#") {
+			flds = append(flds, fld)
+		}
+	}
+	sort.Strings(flds)
+	return flds
+}
+
+func (this *cli) printJSON(v interface{}) error {
+	enc := json.NewEncoder(this.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (this *cli) table() *tabwriter.Writer {
+	return tabwriter.NewWriter(this.out, 0, 4, 2, ' ', 0)
+}
+
+// bucketNode is the JSON form of the buckets tree.
+type bucketNode struct {
+	Name     string       `json:"name"`
+	Recs     int          `json:"recs"`
+	Children []bucketNode `json:"children,omitempty"`
+}
+
+// bucketTree returns the buckets inside path and their nested buckets.
+func bucketTree(path []string) []bucketNode {
+	nodes := make([]bucketNode, 0)
+	for _, info := range bo.ListBuckets(path...) {
+		node := bucketNode{Name: info.Name, Recs: info.Recs}
+		if info.Buckets > 0 {
+			node.Children = bucketTree(append(append([]string(nil), path...), info.Name))
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// buckets prints the bucket tree below path (or the root).
+func (this *cli) buckets(args []string) error {
+	var path []string
+	if len(args) > 0 {
+		path = parsePath(args[0])
+		if path != nil && !bo.BucketExists(path...) {
+			return fmt.Errorf("bucket not found: %s", args[0])
+		}
+	}
+	tree := bucketTree(path)
+	if this.json {
+		return this.printJSON(tree)
+	}
+	w := this.table()
+	fmt.Fprintln(w, "BUCKET\tRECS")
+	var show func(nodes []bucketNode, indent string)
+	show = func(nodes []bucketNode, indent string) {
+		for _, node := range nodes {
+			fmt.Fprintf(w, "%s%s\t%d\n", indent, node.Name, node.Recs)
+			show(node.Children, indent+"  ")
+		}
+	}
+	show(tree, "")
+	return w.Flush()
+}
+
+// get prints 1 rec.
+func (this *cli) get(args []string) error {
+	path, err := bucketPath(args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: get <path> <key>")
+	}
+	key := args[1]
+	var found bo.ValMap
+	recs(path, bo.ScanOpts{Start: key, End: key}, 1, func(k string, vals bo.ValMap) {
+		found = vals
+	})
+	if found == nil {
+		return fmt.Errorf("key not found: %s", key)
+	}
+	if this.json {
+		return this.printJSON(jsonRec{Key: key, Vals: found})
+	}
+	w := this.table()
+	fmt.Fprintf(w, "key\t%s\n", key)
+	for _, fld := range sortedFlds(found) {
+		fmt.Fprintf(w, "%s\t%s\n", fld, found[fld])
+	}
+	return w.Flush()
+}
+
+// scan prints selected recs, 1 row per rec (JSON output is 1 object per line).
+func (this *cli) scan(args []string) error {
+	path, err := bucketPath(args)
+	if err != nil {
+		return err
+	}
+	opts, limit, err := selectFlags("scan", args[1:])
+	if err != nil {
+		return err
+	}
+	if this.json {
+		enc := json.NewEncoder(this.out)
+		recs(path, opts, limit, func(key string, vals bo.ValMap) {
+			if err == nil {
+				err = enc.Encode(jsonRec{Key: key, Vals: vals})
+			}
+		})
+		return err
+	}
+	rows := make([]jsonRec, 0)
+	recs(path, opts, limit, func(key string, vals bo.ValMap) {
+		rows = append(rows, jsonRec{Key: key, Vals: vals})
+	})
+	return this.printRecs(rows)
+}
+
+// printRecs prints rows as aligned columns, 1 column per fld found in any row.
+func (this *cli) printRecs(rows []jsonRec) error {
+	fldSet := make(bo.ValMap)
+	for _, row := range rows {
+		for fld := range row.Vals {
+			fldSet[fld] = ""
+		}
+	}
+	flds := sortedFlds(fldSet)
+	w := this.table()
+	fmt.Fprintln(w, "KEY\t"+strings.Join(flds, "\t"))
+	for _, row := range rows {
+		fmt.Fprint(w, row.Key)
+		for _, fld := range flds {
+			fmt.Fprint(w, "\t"+row.Vals[fld])
+		}
+		fmt.Fprintln(w)
+	}
+	return w.Flush()
+}
+
+// count prints the number of selected recs.
+func (this *cli) count(args []string) error {
+	path, err := bucketPath(args)
+	if err != nil {
+		return err
+	}
+	opts, _, err := selectFlags("count", args[1:])
+	if err != nil {
+		return err
+	}
+	count := recs(path, opts, 0, func(key string, vals bo.ValMap) {})
+	if this.json {
+		return this.printJSON(map[string]int{"count": count})
+	}
+	_, err = fmt.Fprintln(this.out, count)
+	return err
+}
+
+// schema prints the bucket's registered schema.
+// If none is registered, the flds found in the first 1000 recs are printed with type "?".
+func (this *cli) schema(args []string) error {
+	path, err := bucketPath(args)
+	if err != nil {
+		return err
+	}
+	flds := bo.GetSchema(path...)
+	registered := flds != nil
+	if !registered {
+		flds = make(bo.FldMap)
+		recs(path, bo.ScanOpts{}, 1000, func(key string, vals bo.ValMap) {
+			for fld := range vals {
+				flds[fld] = "?"
+			}
+		})
+	}
+	if this.json {
+		return this.printJSON(struct {
+			Registered bool      `json:"registered"`
+			Flds       bo.FldMap `json:"flds"`
+		}{registered, flds})
+	}
+	if !registered {
+		fmt.Fprintln(this.out, "no schema registered, flds found in recs:")
+	}
+	names := make([]string, 0, len(flds))
+	for fld := range flds {
+		names = append(names, fld)
+	}
+	sort.Strings(names)
+	w := this.table()
+	fmt.Fprintln(w, "FLD\tTYPE")
+	for _, fld := range names {
+		fmt.Fprintf(w, "%s\t%s\n", fld, flds[fld])
+	}
+	return w.Flush()
+}
+
+// stats prints statistics of path (or all buckets) and its nested buckets.
+func (this *cli) stats(args []string) error {
+	var path []string
+	if len(args) > 0 {
+		var err error
+		if path, err = bucketPath(args); err != nil {
+			return err
+		}
+	}
+	stats := bo.Stats(path...)
+	if this.json {
+		return this.printJSON(stats)
+	}
+	w := this.table()
+	fmt.Fprintln(w, "BUCKET\tDEPTH\tRECS\tBUCKETS\tKEY MIN/AVG/MAX\tVAL MIN/AVG/P90/MAX\tLEAF PAGES\tLEAF INUSE\tSCHEMA")
+	for _, s := range stats {
+		schema := "-"
+		if s.Schema != nil {
+			schema = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d/%.1f/%d\t%d/%.1f/%d/%d\t%d\t%d\t%s\n",
+			strings.Join(s.BktPath, "/"), s.Depth, s.Recs, s.Buckets,
+			s.KeySize.Min, s.KeySize.Mean, s.KeySize.Max,
+			s.ValSize.Min, s.ValSize.Mean, s.ValSize.P90, s.ValSize.Max,
+			s.Bolt.LeafPageN, s.Bolt.LeafInuse, schema)
+	}
+	return w.Flush()
+}
+
+// export writes the bucket as JSON Lines to file (or out if no file).
+func (this *cli) export(args []string) error {
+	path, err := bucketPath(args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 2 {
+		return fmt.Errorf("unexpected argument: %s", args[2])
+	}
+	if len(args) == 1 {
+		_, err = bo.Export(path, this.out)
+		return err
+	}
+	f, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+	count, err := bo.Export(path, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(this.out, "%d recs exported\n", count)
+	return err
+}
+
+// importLines reads JSON Lines from file (or in if no file) into the bucket, which is created if missing.
+func (this *cli) importLines(args []string) error {
+	if len(args) == 0 || len(parsePath(args[0])) == 0 {
+		return fmt.Errorf("bucket path required")
+	}
+	path := parsePath(args[0])
+	var mode string
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.StringVar(&mode, "mode", bo.ImportReplace, "replace, merge or skipExisting")
+	files := make([]string, 0, 1)
+	for rest := args[1:]; ; rest = flags.Args()[1:] { // flags can be before or after file
+		if err := flags.Parse(rest); err != nil {
+			return err
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+	}
+	if mode != bo.ImportReplace && mode != bo.ImportMerge && mode != bo.ImportSkip {
+		return fmt.Errorf("--mode must be %s, %s or %s", bo.ImportReplace, bo.ImportMerge, bo.ImportSkip)
+	}
+	if len(files) > 1 {
+		return fmt.Errorf("unexpected argument: %s", files[1])
+	}
+	in := this.in
+	if len(files) == 1 {
+		f, err := os.Open(files[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	result, err := bo.Import(path, in, mode)
+	if this.json {
+		if err == nil {
+			err = this.printJSON(result)
+		}
+		return err
+	}
+	fmt.Fprintf(this.out, "%d added, %d updated, %d skipped\n", result.Added, result.Updated, result.Skipped)
+	return err
+}
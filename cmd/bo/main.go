@@ -0,0 +1,127 @@
+// Command bo inspects Bo (BoltDB) database files.
+//
+// Usage:
+//
+//	bo [-json] <dbfile> <command> [args]
+//	bo shell <dbfile>
+//
+// Commands:
+//
+//	buckets [path]                                     bucket tree with rec counts
+//	get <path> <key>                                   1 rec
+//	scan <path> [--prefix p] [--range start..end] [--limit n] [--reverse]
+//	count <path> [--prefix p] [--range start..end]     number of recs
+//	schema <path>                                      registered schema (or flds found in recs)
+//	stats [path]                                       bucket statistics
+//	export <path> [file]                               JSON Lines, to stdout if no file
+//	import <path> [file] [--mode m]                    JSON Lines, from stdin if no file
+//
+// Import modes are replace (default), merge and skipExisting, see bo.Import.
+// The shell is interactive (type help for its commands), it can change the database.
+// Paths are bucket names separated by "/", ex. "tenants/acme/orders".
+// The database is opened read only (except by import and shell).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/boltdb/bolt"
+	"github.com/txjmp/bo"
+	"io"
+	"os"
+	"time"
+)
+
+const usage = `usage: bo [-json] <dbfile> <command> [args]
+       bo shell <dbfile>
+commands:
+  buckets [path]
+  get <path> <key>
+  scan <path> [--prefix p] [--range start..end] [--limit n] [--reverse]
+  count <path> [--prefix p] [--range start..end]
+  schema <path>
+  stats [path]
+  export <path> [file]
+  import <path> [file] [--mode replace|merge|skipExisting]
+`
+
+func main() {
+	jsonOut := flag.Bool("json", false, "print output as JSON")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if flag.Arg(0) == "shell" {
+		shellMain(flag.Arg(1))
+		return
+	}
+	readOnly := flag.Arg(1) != "import"
+	db, err := bolt.Open(flag.Arg(0), 0600, &bolt.Options{ReadOnly: readOnly, Timeout: 5 * time.Second})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bo:", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	bo.Setdb(db)
+	cli := &cli{out: os.Stdout, in: os.Stdin, json: *jsonOut}
+	if err := cli.run(flag.Args()[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "bo:", err)
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// shellMain opens fileName for reading and writing and runs the interactive shell.
+func shellMain(fileName string) {
+	db, err := bolt.Open(fileName, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bo:", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+	bo.Setdb(db)
+	if err := runShell(); err != nil {
+		fmt.Fprintln(os.Stderr, "bo:", err)
+	}
+}
+
+// cli runs commands, writing results to out. Import reads from in if no file is given.
+type cli struct {
+	out  io.Writer
+	in   io.Reader
+	json bool
+}
+
+// run executes the command in args, bo's panics are returned as errors.
+func (this *cli) run(args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	if len(args) == 0 {
+		return fmt.Errorf("command required\n%s", usage)
+	}
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "buckets":
+		return this.buckets(args)
+	case "get":
+		return this.get(args)
+	case "scan":
+		return this.scan(args)
+	case "count":
+		return this.count(args)
+	case "schema":
+		return this.schema(args)
+	case "stats":
+		return this.stats(args)
+	case "export":
+		return this.export(args)
+	case "import":
+		return this.importLines(args)
+	}
+	return fmt.Errorf("unknown command %q\n%s", cmd, usage)
+}
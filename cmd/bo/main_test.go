@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"github.com/boltdb/bolt"
+	"github.com/txjmp/bo"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testDB creates a db with "shop/items" (3 recs) and "shop/items/archive" (1 rec).
+func testDB(t *testing.T) func() {
+	fileName := filepath.Join(os.TempDir(), "bo-cmd-test.db")
+	os.Remove(fileName)
+	db, err := bolt.Open(fileName, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bo.Setdb(db)
+	bo.CreateBucketIfNotExists("shop", "items", "archive")
+	items := bo.NewTable(bo.FldMap{"name": "str", "price": "float"}, bo.NotShared, "shop", "items")
+	items.CreateRecMap()
+	items.AddRec("a1", bo.ValMap{"name": "apple", "price": "0.5"})
+	items.AddRec("a2", bo.ValMap{"name": "avocado", "price": "1.25"})
+	items.AddRec("b1", bo.ValMap{"name": "bread"})
+	tx := bo.StartDBWrite()
+	items.Save(tx)
+	bo.CommitDBWrite(tx)
+	items.RegisterSchema()
+	archive := bo.NewTable(bo.FldMap{"name": "str"}, bo.NotShared, "shop", "items", "archive")
+	archive.CreateRecMap()
+	archive.AddRec("z9", bo.ValMap{"name": "old"})
+	tx = bo.StartDBWrite()
+	archive.Save(tx)
+	bo.CommitDBWrite(tx)
+	return func() {
+		db.Close()
+		os.Remove(fileName)
+	}
+}
+
+// runCmd runs args, returning output.
+func runCmd(t *testing.T, jsonOut bool, args ...string) string {
+	var out bytes.Buffer
+	c := &cli{out: &out, json: jsonOut}
+	if err := c.run(args); err != nil {
+		t.Fatal(args, err)
+	}
+	return out.String()
+}
+
+func TestCommands(t *testing.T) {
+	defer testDB(t)()
+
+	out := runCmd(t, false, "buckets")
+	if !strings.Contains(out, "\nshop ") || !strings.Contains(out, "\n  items      3") || !strings.Contains(out, "\n    archive  1") {
+		t.Fatal("buckets wrong:\n", out)
+	}
+	if strings.Contains(out, bo.MetaBktName) { // created by RegisterSchema
+		t.Fatal("buckets shows metadata bucket:\n", out)
+	}
+	out = runCmd(t, false, "get", "shop/items", "a2")
+	if !strings.Contains(out, "name   avocado") || !strings.Contains(out, "price  1.25") {
+		t.Fatal("get wrong:\n", out)
+	}
+	out = runCmd(t, true, "get", "shop/items", "a2")
+	if !strings.Contains(out, `"key": "a2"`) || !strings.Contains(out, `"name": "avocado"`) {
+		t.Fatal("get json wrong:\n", out)
+	}
+	out = runCmd(t, false, "scan", "shop/items", "--prefix", "a", "--limit", "1")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "a1   apple") {
+		t.Fatal("scan wrong:\n", out)
+	}
+	out = runCmd(t, true, "scan", "shop/items", "--range", "a2..b1", "--reverse")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[0], `{"key":"b1"`) {
+		t.Fatal("scan json wrong:\n", out)
+	}
+	if out = runCmd(t, false, "count", "shop/items"); out != "3\n" {
+		t.Fatal("count wrong: ", out)
+	}
+	if out = runCmd(t, false, "schema", "shop/items"); !strings.Contains(out, "price  float") {
+		t.Fatal("schema wrong:\n", out)
+	}
+	if out = runCmd(t, false, "schema", "shop/items/archive"); !strings.Contains(out, "no schema") || !strings.Contains(out, "name  ?") {
+		t.Fatal("inferred schema wrong:\n", out)
+	}
+	out = runCmd(t, false, "stats", "shop")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 4 || !strings.HasPrefix(lines[2], "shop/items ") {
+		t.Fatal("stats wrong:\n", out)
+	}
+
+	c := &cli{out: &bytes.Buffer{}}
+	if err := c.run([]string{"get", "shop/items", "zz"}); err == nil {
+		t.Fatal("get missing key did not return error")
+	}
+	if err := c.run([]string{"count", "nosuch"}); err == nil {
+		t.Fatal("missing bucket did not return error")
+	}
+}
+
+func TestExportImportCommands(t *testing.T) {
+	defer testDB(t)()
+
+	out := runCmd(t, false, "export", "shop/items")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 6 || lines[4] != `{"bkt":["archive"],"seq":0}` {
+		t.Fatal("export wrong:\n", out)
+	}
+	var result bytes.Buffer
+	c := &cli{out: &result, in: strings.NewReader(out)}
+	if err := c.run([]string{"import", "backup/items", "--mode", "skipExisting"}); err != nil || result.String() != "4 added, 0 updated, 0 skipped\n" {
+		t.Fatal("import wrong: ", result.String(), err)
+	}
+	if out = runCmd(t, false, "get", "backup/items/archive", "z9"); !strings.Contains(out, "name  old") {
+		t.Fatal("imported nested bucket wrong:\n", out)
+	}
+	if err := c.run([]string{"import", "backup/items", "--mode", "upsert"}); err == nil {
+		t.Fatal("invalid mode did not return error")
+	}
+
+	fileName := filepath.Join(os.TempDir(), "bo-cmd-test.jsonl")
+	defer os.Remove(fileName)
+	if out = runCmd(t, false, "export", "shop/items", fileName); out != "4 recs exported\n" {
+		t.Fatal("export to file wrong: ", out)
+	}
+	result.Reset()
+	if err := c.run([]string{"import", "backup/items", fileName, "--mode", "merge"}); err != nil || result.String() != "0 added, 4 updated, 0 skipped\n" {
+		t.Fatal("import file with mode after it wrong: ", result.String(), err)
+	}
+}
@@ -0,0 +1,646 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/txjmp/bo"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const shellHelp = `commands (paths are relative to current bucket, "/" is the root, ".." the parent):
+  cd [path]                        change current bucket
+  pwd                              show current bucket
+  ls [path]                        nested buckets and rec keys
+  get <key>                        show rec
+  put <key> fld=val [fld=val ...]  add or change rec (use quotes for vals with spaces)
+  delete <key>                     delete rec
+  find [fld op val ...] [sort fld[:opts] ...] [limit n]
+                                   ops: = != < <= > >= ~ (contains), sort opts as in CreateOrderBy
+  sort fld[:opts] ... [limit n]    same as find with no filters
+  scan, count, schema, stats       same as bo command, for current bucket
+  begin                            start write session, put and delete are saved by commit
+  commit                           save session changes in 1 transaction
+  rollback                         discard session changes
+  exit                             end shell (uncommitted changes are discarded)
+without begin, put and delete are saved immediately
+`
+
+// shell is an interactive session with 1 database.
+type shell struct {
+	cli     *cli
+	path    []string             // current bucket, nil is the root
+	session map[string]*bo.Table // tables changed since begin, key is path, nil if no session
+	done    bool
+}
+
+// lineReader reads 1 command line, term.Terminal or bufio.Scanner.
+type lineReader interface {
+	ReadLine() (string, error)
+}
+
+type scanReader struct {
+	scanner *bufio.Scanner
+}
+
+func (this scanReader) ReadLine() (string, error) {
+	if !this.scanner.Scan() {
+		if err := this.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return this.scanner.Text(), nil
+}
+
+// runShell runs the shell, using a terminal with line editing and tab completion if stdin is one.
+func runShell() error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		sh := &shell{cli: &cli{out: os.Stdout}}
+		return sh.loop(scanReader{bufio.NewScanner(os.Stdin)}, nil)
+	}
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, oldState)
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	sh := &shell{cli: &cli{out: terminal}}
+	terminal.AutoCompleteCallback = sh.autoComplete
+	return sh.loop(terminal, terminal.SetPrompt)
+}
+
+// loop reads and executes commands until exit or end of input.
+func (this *shell) loop(in lineReader, setPrompt func(string)) error {
+	for !this.done {
+		if setPrompt != nil {
+			setPrompt(this.prompt())
+		}
+		line, err := in.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := this.exec(line); err != nil {
+			fmt.Fprintln(this.cli.out, "error:", err)
+		}
+	}
+	return nil
+}
+
+func (this *shell) prompt() string {
+	prompt := "bo:/" + strings.Join(this.path, "/")
+	if this.session != nil {
+		prompt += "*"
+	}
+	return prompt + "> "
+}
+
+// splitArgs splits line at spaces, text inside double quotes is 1 arg.
+func splitArgs(line string) []string {
+	args := make([]string, 0)
+	var arg strings.Builder
+	var quoted, inArg bool
+	for _, c := range line {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case c == ' ' && !quoted:
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// resolve returns the bucket path of arg, relative to the current bucket.
+func (this *shell) resolve(arg string) []string {
+	path := append([]string(nil), this.path...)
+	if strings.HasPrefix(arg, "/") {
+		path = nil
+	}
+	for _, name := range strings.Split(arg, "/") {
+		switch name {
+		case "", ".":
+		case "..":
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+		default:
+			path = append(path, name)
+		}
+	}
+	return path
+}
+
+// exec runs 1 command line, bo's panics are returned as errors.
+func (this *shell) exec(line string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	args := splitArgs(line)
+	if len(args) == 0 {
+		return nil
+	}
+	cmd, args := args[0], args[1:]
+	here := strings.Join(this.path, "/")
+	switch cmd {
+	case "help":
+		fmt.Fprint(this.cli.out, shellHelp)
+	case "exit", "quit":
+		this.done = true
+	case "pwd":
+		fmt.Fprintln(this.cli.out, "/"+here)
+	case "cd":
+		return this.cd(args)
+	case "ls":
+		return this.ls(args)
+	case "get":
+		return this.get(args)
+	case "put":
+		return this.put(args)
+	case "delete":
+		return this.delete(args)
+	case "find":
+		return this.find(args)
+	case "sort":
+		return this.find(append([]string{"sort"}, args...))
+	case "scan", "count", "schema":
+		return this.cli.run(append([]string{cmd, here}, args...))
+	case "stats":
+		if here == "" {
+			return this.cli.run([]string{cmd})
+		}
+		return this.cli.run([]string{cmd, here})
+	case "begin":
+		if this.session != nil {
+			return fmt.Errorf("session already started")
+		}
+		this.session = make(map[string]*bo.Table)
+	case "commit":
+		return this.commit()
+	case "rollback":
+		if this.session == nil {
+			return fmt.Errorf("no session")
+		}
+		this.session = nil
+	default:
+		return fmt.Errorf("unknown command %q, try help", cmd)
+	}
+	return nil
+}
+
+func (this *shell) cd(args []string) error {
+	if len(args) == 0 {
+		this.path = nil
+		return nil
+	}
+	path := this.resolve(args[0])
+	if len(path) > 0 && !bo.BucketExists(path...) {
+		return fmt.Errorf("bucket not found: /%s", strings.Join(path, "/"))
+	}
+	this.path = path
+	return nil
+}
+
+// ls lists nested buckets (name followed by "/" and rec count), then up to 100 rec keys.
+func (this *shell) ls(args []string) error {
+	path := this.path
+	if len(args) > 0 {
+		path = this.resolve(args[0])
+	}
+	if len(path) > 0 && !bo.BucketExists(path...) {
+		return fmt.Errorf("bucket not found: /%s", strings.Join(path, "/"))
+	}
+	w := this.cli.table()
+	for _, info := range bo.ListBuckets(path...) {
+		fmt.Fprintf(w, "%s/\t%d recs\n", info.Name, info.Recs)
+	}
+	if len(path) > 0 {
+		const max = 100
+		count := recs(path, bo.ScanOpts{}, max+1, func(key string, vals bo.ValMap) {})
+		recs(path, bo.ScanOpts{}, max, func(key string, vals bo.ValMap) {
+			fmt.Fprintln(w, key)
+		})
+		if count > max {
+			fmt.Fprintln(w, "... (use count or scan)")
+		}
+	}
+	return w.Flush()
+}
+
+// requireBucket returns error if the current bucket is the root.
+func (this *shell) requireBucket() error {
+	if len(this.path) == 0 {
+		return fmt.Errorf("cd to a bucket first")
+	}
+	return nil
+}
+
+// table returns the Table used by put and delete for the current bucket.
+// In a session, the same Table is returned until commit or rollback.
+func (this *shell) table() *bo.Table {
+	name := strings.Join(this.path, "/")
+	if tbl, found := this.session[name]; found {
+		return tbl
+	}
+	flds := bo.GetSchema(this.path...)
+	if flds == nil {
+		flds = make(bo.FldMap)
+	}
+	tbl := bo.NewTable(flds, bo.NotShared, this.path...)
+	tbl.CreateRecMap()
+	if this.session != nil {
+		this.session[name] = tbl
+	}
+	return tbl
+}
+
+// sessionRec returns the rec with key changed in the current session, nil if none.
+func (this *shell) sessionRec(key string) *bo.Rec {
+	if tbl, found := this.session[strings.Join(this.path, "/")]; found {
+		return tbl.GetRec(key)
+	}
+	return nil
+}
+
+func (this *shell) get(args []string) error {
+	if err := this.requireBucket(); err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: get <key>")
+	}
+	if rec := this.sessionRec(args[0]); rec != nil {
+		if rec.Vals["#delete"] == "1" {
+			return fmt.Errorf("key deleted in session: %s", args[0])
+		}
+		w := this.cli.table()
+		fmt.Fprintf(w, "key\t%s (uncommitted)\n", args[0])
+		for _, fld := range sortedFlds(rec.Vals) {
+			fmt.Fprintf(w, "%s\t%s\n", fld, rec.Vals[fld])
+		}
+		return w.Flush()
+	}
+	return this.cli.get([]string{strings.Join(this.path, "/"), args[0]})
+}
+
+// save writes tbl's changes, unless a session is open.
+func (this *shell) save(tbl *bo.Table) {
+	if this.session != nil {
+		return
+	}
+	tx := bo.StartDBWrite()
+	tbl.Save(tx)
+	bo.CommitDBWrite(tx)
+}
+
+func (this *shell) put(args []string) error {
+	if err := this.requireBucket(); err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("usage: put <key> fld=val [fld=val ...]")
+	}
+	key := args[0]
+	vals := make(bo.ValMap)
+	for _, arg := range args[1:] {
+		x := strings.Index(arg, "=")
+		if x < 1 {
+			return fmt.Errorf("invalid fld=val: %s", arg)
+		}
+		vals[arg[:x]] = arg[x+1:]
+	}
+	tbl := this.table()
+	hasSchema := bo.GetSchema(this.path...) != nil
+	for fld, val := range vals { // validate all flds before changing anything
+		valType, found := tbl.Flds[fld]
+		if !found && hasSchema {
+			return fmt.Errorf("fld %s not in schema", fld)
+		}
+		if strings.ContainsRune(key+fld+val, '"') {
+			return fmt.Errorf("key, fld and val cannot contain quotes: %s", fld)
+		}
+		if found && val != "" {
+			converted, err := bo.ConvertVal(val, valType)
+			if err != nil {
+				return fmt.Errorf("fld %s: %v", fld, err)
+			}
+			vals[fld] = converted
+		}
+	}
+	rec := tbl.GetRec(key)
+	if rec == nil {
+		tbl.Merge1(key)
+		rec = tbl.GetRec(key)
+	}
+	if rec == nil {
+		rec = tbl.AddRec(key)
+	}
+	for fld, val := range vals {
+		if _, found := tbl.Flds[fld]; !found {
+			tbl.Flds[fld] = "str"
+		}
+		rec.Set(fld, val)
+	}
+	delete(rec.Vals, "#delete")
+	this.save(tbl)
+	return nil
+}
+
+func (this *shell) delete(args []string) error {
+	if err := this.requireBucket(); err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: delete <key>")
+	}
+	tbl := this.table()
+	if tbl.GetRec(args[0]) == nil {
+		tbl.Merge1(args[0])
+	}
+	if tbl.GetRec(args[0]) == nil {
+		return fmt.Errorf("key not found: %s", args[0])
+	}
+	tbl.DeleteRec(args[0])
+	this.save(tbl)
+	return nil
+}
+
+func (this *shell) commit() error {
+	if this.session == nil {
+		return fmt.Errorf("no session")
+	}
+	tx := bo.StartDBWrite()
+	var count int
+	for _, tbl := range this.session {
+		count += tbl.Save(tx)
+	}
+	bo.CommitDBWrite(tx)
+	this.session = nil
+	fmt.Fprintln(this.cli.out, count, "recs saved")
+	return nil
+}
+
+var filterExpr = regexp.MustCompile(`^([^=!<>~]+)(!=|<=|>=|=|<|>|~)(.*)$`)
+
+// filter is 1 find condition, ex. price>=10.
+type filter struct {
+	fld, op, val string
+}
+
+// match returns true if vals matches the filter. Vals that are both numbers are compared as numbers.
+func (this filter) match(vals bo.ValMap) bool {
+	val, found := vals[this.fld]
+	if this.op == "~" {
+		return found && strings.Contains(val, this.val)
+	}
+	result := strings.Compare(val, this.val)
+	x, err1 := strconv.ParseFloat(val, 64)
+	y, err2 := strconv.ParseFloat(this.val, 64)
+	if err1 == nil && err2 == nil {
+		switch {
+		case x < y:
+			result = -1
+		case x > y:
+			result = 1
+		default:
+			result = 0
+		}
+	}
+	switch this.op {
+	case "=":
+		return result == 0
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	}
+	return result >= 0 // ">="
+}
+
+// inferFlds returns a FldMap for recs, a fld is int or float if all its vals are, else str.
+func inferFlds(recs map[string]bo.ValMap) bo.FldMap {
+	flds := make(bo.FldMap)
+	for _, vals := range recs {
+		for fld, val := range vals {
+			if strings.HasPrefix(fld, "#") || val == "" {
+				continue
+			}
+			valType := "str"
+			if _, err := strconv.ParseInt(val, 10, 64); err == nil {
+				valType = "int"
+			} else if _, err := strconv.ParseFloat(val, 64); err == nil {
+				valType = "float"
+			}
+			switch {
+			case flds[fld] == "" || flds[fld] == valType:
+				flds[fld] = valType
+			case flds[fld] != "str" && valType != "str":
+				flds[fld] = "float" // mix of int and float
+			default:
+				flds[fld] = "str"
+			}
+		}
+	}
+	return flds
+}
+
+// find prints recs in the current bucket matching all filters, sorted with Bo's CreateOrderBy.
+func (this *shell) find(args []string) error {
+	if err := this.requireBucket(); err != nil {
+		return err
+	}
+	filters := make([]filter, 0)
+	sortBy := make([]string, 0)
+	limit := 0
+	sorting := false
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "sort":
+			sorting = true
+		case args[i] == "limit" && i+1 < len(args):
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid limit: %s", args[i+1])
+			}
+			limit = n
+			i++
+		case sorting:
+			sortBy = append(sortBy, args[i])
+		default:
+			parts := filterExpr.FindStringSubmatch(args[i])
+			if parts == nil {
+				return fmt.Errorf("invalid filter: %s", args[i])
+			}
+			filters = append(filters, filter{fld: parts[1], op: parts[2], val: parts[3]})
+		}
+	}
+	found := make(map[string]bo.ValMap)
+	recs(this.path, bo.ScanOpts{}, 0, func(key string, vals bo.ValMap) {
+		for _, f := range filters {
+			if !f.match(vals) {
+				return
+			}
+		}
+		found[key] = vals
+	})
+	flds := bo.GetSchema(this.path...)
+	if flds == nil {
+		flds = inferFlds(found)
+	}
+	tbl := bo.NewTable(flds, bo.NotShared, this.path...)
+	tbl.CreateRecMap()
+	keys := make([]string, 0, len(found))
+	for key, vals := range found {
+		tbl.AddRec(key, vals)
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(sortBy) > 0 {
+		tbl.CreateOrderBy("find", sortBy...)
+		keys = tbl.OrderBy["find"]
+	}
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	rows := make([]jsonRec, len(keys))
+	for i, key := range keys {
+		rows[i] = jsonRec{Key: key, Vals: found[key]}
+	}
+	return this.cli.printRecs(rows)
+}
+
+// --- tab completion ---------------------------------------
+
+var shellCommands = []string{"begin", "cd", "commit", "count", "delete", "exit", "find", "get",
+	"help", "ls", "put", "pwd", "rollback", "scan", "schema", "sort", "stats"}
+
+// complete returns the possible completions of the last word in line.
+func (this *shell) complete(line string) []string {
+	words := strings.Fields(line)
+	partial := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		partial = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	candidates := make([]string, 0)
+	add := func(word string) {
+		if strings.HasPrefix(word, partial) {
+			candidates = append(candidates, word)
+		}
+	}
+	if len(words) == 0 {
+		for _, cmd := range shellCommands {
+			add(cmd)
+		}
+		return candidates
+	}
+	switch cmd := words[0]; {
+	case cmd == "cd" || cmd == "ls":
+		dir := ""
+		if x := strings.LastIndex(partial, "/"); x != -1 {
+			dir = partial[:x+1]
+		}
+		path := this.resolve(dir)
+		if len(path) > 0 && !bo.BucketExists(path...) {
+			return candidates
+		}
+		for _, info := range bo.ListBuckets(path...) {
+			add(dir + info.Name + "/")
+		}
+	case len(this.path) == 0:
+	case (cmd == "get" || cmd == "delete" || cmd == "put") && len(words) == 1:
+		recs(this.path, bo.ScanOpts{Prefix: partial}, 50, func(key string, vals bo.ValMap) {
+			add(key)
+		})
+	case cmd == "put":
+		for _, fld := range this.fldNames() {
+			add(fld + "=")
+		}
+	case cmd == "find" || cmd == "sort":
+		for _, fld := range this.fldNames() {
+			add(fld)
+		}
+		add("sort")
+		add("limit")
+	}
+	return candidates
+}
+
+// fldNames returns fld names of the current bucket's schema, or found in its first 100 recs.
+func (this *shell) fldNames() []string {
+	flds := bo.GetSchema(this.path...)
+	if flds == nil {
+		flds = make(bo.FldMap)
+		recs(this.path, bo.ScanOpts{}, 100, func(key string, vals bo.ValMap) {
+			for fld := range vals {
+				flds[fld] = ""
+			}
+		})
+	}
+	names := make([]string, 0, len(flds))
+	for fld := range flds {
+		names = append(names, fld)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// autoComplete is the term.Terminal AutoCompleteCallback, completing the word before pos on tab.
+func (this *shell) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	before := line[:pos]
+	candidates := this.complete(before)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+	common := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	if len(candidates) == 1 && !strings.HasSuffix(common, "/") && !strings.HasSuffix(common, "=") {
+		common += " "
+	}
+	start := len(before)
+	if !strings.HasSuffix(before, " ") {
+		if x := strings.LastIndex(before, " "); x != -1 {
+			start = x + 1
+		} else {
+			start = 0
+		}
+	}
+	newLine := before[:start] + common + line[pos:]
+	return newLine, start + len(common), true
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// execLines runs shell command lines, returning output. Fails if a command returns error.
+func execLines(t *testing.T, sh *shell, lines ...string) string {
+	out := sh.cli.out.(*bytes.Buffer)
+	out.Reset()
+	for _, line := range lines {
+		if err := sh.exec(line); err != nil {
+			t.Fatal(line, ": ", err)
+		}
+	}
+	return out.String()
+}
+
+func TestShell(t *testing.T) {
+	defer testDB(t)()
+	sh := &shell{cli: &cli{out: &bytes.Buffer{}}}
+
+	out := execLines(t, sh, "cd shop/items", "pwd", "ls")
+	if !strings.HasPrefix(out, "/shop/items\narchive/  1 recs\na1") || sh.prompt() != "bo:/shop/items> " {
+		t.Fatal("cd or ls wrong:\n", out)
+	}
+	out = execLines(t, sh, `put c1 name="cheese wheel" price=9`, "get c1")
+	if !strings.Contains(out, "name   cheese wheel") {
+		t.Fatal("put without session wrong:\n", out)
+	}
+
+	out = execLines(t, sh, "find price>=1 sort price:d")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "c1") || !strings.HasPrefix(lines[2], "a2") {
+		t.Fatal("find sorted wrong:\n", out)
+	}
+	out = execLines(t, sh, "find name~br")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], "b1") {
+		t.Fatal("find contains wrong:\n", out)
+	}
+	out = execLines(t, sh, "sort name:d limit 2")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "c1") {
+		t.Fatal("sort wrong:\n", out)
+	}
+
+	execLines(t, sh, "begin", "delete a1", "put a2 price=2")
+	if sh.prompt() != "bo:/shop/items*> " {
+		t.Fatal("session prompt wrong: ", sh.prompt())
+	}
+	if out = execLines(t, sh, "count"); out != "4\n" {
+		t.Fatal("session changes saved before commit: ", out)
+	}
+	out = execLines(t, sh, "commit", "count", "get a2")
+	if !strings.HasPrefix(out, "2 recs saved\n3\n") || !strings.Contains(out, "price  2") || !strings.Contains(out, "avocado") {
+		t.Fatal("commit wrong:\n", out)
+	}
+	execLines(t, sh, "begin", "delete b1", "rollback")
+	if out = execLines(t, sh, "count"); out != "3\n" {
+		t.Fatal("rollback wrong: ", out)
+	}
+	if err := sh.exec("put x1 color=red"); err == nil {
+		t.Fatal("put fld not in schema did not return error")
+	}
+	execLines(t, sh, "begin")
+	if err := sh.exec("put a2 name=changed color=red"); err == nil {
+		t.Fatal("put fld not in schema did not return error")
+	}
+	if out = execLines(t, sh, "commit", "get a2"); !strings.Contains(out, "avocado") {
+		t.Fatal("failed put changed session rec:\n", out)
+	}
+	if err := sh.exec("put a2 price=abc"); err == nil {
+		t.Fatal("put invalid float did not return error")
+	}
+	out = execLines(t, sh, "put a2 price=3.50", "get a2", "find sort price")
+	if !strings.Contains(out, "price  3.5\n") || strings.Contains(out, "abc") {
+		t.Fatal("put float val wrong:\n", out)
+	}
+
+	complete := func(line string) string {
+		return strings.Join(sh.complete(line), " ")
+	}
+	if got := complete("ar"); got != "" {
+		t.Fatal("command completion wrong: ", got)
+	}
+	if got := complete("co"); got != "commit count" {
+		t.Fatal("command completion wrong: ", got)
+	}
+	if got := complete("cd ar"); got != "archive/" {
+		t.Fatal("bucket completion wrong: ", got)
+	}
+	if got := complete("cd ../"); got != "../items/" {
+		t.Fatal("relative bucket completion wrong: ", got)
+	}
+	if got := complete("get a"); got != "a2" {
+		t.Fatal("key completion wrong: ", got)
+	}
+	if got := complete("find p"); got != "price" {
+		t.Fatal("fld completion wrong: ", got)
+	}
+	if line, pos, ok := sh.autoComplete("put a2 na", 9, '\t'); !ok || line != "put a2 name=" || pos != 12 {
+		t.Fatal("autoComplete wrong: ", line, pos)
+	}
+}
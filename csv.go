@@ -0,0 +1,308 @@
+package bo
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVOpts are options of Table.ExportCSV and Table.ImportCSV, zero values use the defaults.
+type CSVOpts struct {
+	KeyCol         string            // header of key column, default "key"
+	NoKey          bool              // export without key column, import ignores key column (keys are generated)
+	Flds           []string          // export columns in order, default all Flds sorted by name
+	OrderBy        string            // export recs in OrderBy[OrderBy] order, default key order
+	ColMap         map[string]string // import column header -> fld name, fld "" means column is ignored
+	DateFormat     string            // format of date values in the file, default DateFormat
+	DateTimeFormat string            // format of dateTime values in the file, default DateTimeFormat
+	Comma          rune              // field delimiter, default ','
+}
+
+// defaults returns opts with defaults set.
+func (this CSVOpts) defaults() CSVOpts {
+	if this.KeyCol == "" {
+		this.KeyCol = "key"
+	}
+	if this.DateFormat == "" {
+		this.DateFormat = DateFormat
+	}
+	if this.DateTimeFormat == "" {
+		this.DateTimeFormat = DateTimeFormat
+	}
+	if this.Comma == 0 {
+		this.Comma = ','
+	}
+	return this
+}
+
+// CSVError is an error in 1 row of an imported file.
+type CSVError struct {
+	Line int    // line number in file, 1 is the header
+	Col  string // column header, "" if error is not about 1 column
+	Msg  string
+}
+
+func (this *CSVError) Error() string {
+	if this.Col == "" {
+		return fmt.Sprintf("line %d: %s", this.Line, this.Msg)
+	}
+	return fmt.Sprintf("line %d, column %s: %s", this.Line, this.Col, this.Msg)
+}
+
+// ExportCSV writes recs in RecMap to w, 1 row per rec after a header row of column names.
+// Date and dateTime values are written using opts.DateFormat and opts.DateTimeFormat.
+func (this *Table) ExportCSV(w io.Writer, opts CSVOpts) error {
+	opts = opts.defaults()
+	flds := opts.Flds
+	if flds == nil {
+		for fld := range this.Flds {
+			flds = append(flds, fld)
+		}
+		sort.Strings(flds)
+	}
+	header := make([]string, 0, len(flds)+1)
+	if !opts.NoKey {
+		header = append(header, opts.KeyCol)
+	}
+	for _, fld := range flds {
+		if _, found := this.Flds[fld]; !found {
+			return fmt.Errorf("ExportCSV invalid fld: %s", fld)
+		}
+		header = append(header, fld)
+	}
+	var keys []string
+	if opts.OrderBy != "" {
+		if keys = this.OrderBy[opts.OrderBy]; keys == nil {
+			return fmt.Errorf("ExportCSV orderBy not found: %s", opts.OrderBy)
+		}
+	} else {
+		keys = make([]string, 0, len(this.RecMap))
+		for key := range this.RecMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	out := csv.NewWriter(w)
+	out.Comma = opts.Comma
+	out.Write(header)
+	row := make([]string, len(header))
+	this.loopKeys(func(key string, rec *Rec) {
+		i := 0
+		if !opts.NoKey {
+			row[0] = key
+			i = 1
+		}
+		for _, fld := range flds {
+			row[i] = this.exportVal(rec.Vals[fld], this.Flds[fld], opts)
+			i++
+		}
+		out.Write(row)
+	}, keys)
+	out.Flush()
+	return out.Error()
+}
+
+// exportVal converts a stored date or dateTime val to the file's format. Other vals are not changed.
+func (this *Table) exportVal(val, valType string, opts CSVOpts) string {
+	if val == "" {
+		return val
+	}
+	switch valType {
+	case "date":
+		if t, err := time.Parse(DateFormat, val); err == nil {
+			return t.Format(opts.DateFormat)
+		}
+	case "dateTime":
+		if t, err := time.Parse(DateTimeFormat, val); err == nil {
+			return t.Format(opts.DateTimeFormat)
+		}
+	}
+	return val
+}
+
+// ImportCSV adds a rec to RecMap for each row of r (use Save to write them to the db).
+// The first row is the header, columns are matched to flds by name (or opts.ColMap).
+// Vals are converted and validated by fld type, empty vals are not set.
+// If there is no key column (or opts.NoKey), keys are created by NewKeys.
+// Rows with errors are not added, their errors are returned with the file's line number.
+// A key already in RecMap or on a previous line is an error. Recs in the db that are not loaded
+// are not checked, Save replaces them (or merges imported flds into them if MergeSave is true).
+// Returns count of recs added.
+func (this *Table) ImportCSV(r io.Reader, opts CSVOpts) (int, []error) {
+	opts = opts.defaults()
+	in := csv.NewReader(r)
+	in.Comma = opts.Comma
+	in.FieldsPerRecord = -1
+	in.TrimLeadingSpace = true
+
+	header, err := in.Read()
+	if err != nil {
+		return 0, []error{&CSVError{Line: 1, Msg: "header: " + err.Error()}}
+	}
+	keyIdx := -1
+	flds := make([]string, len(header)) // fld of each column, "" = ignored
+	for i, col := range header {
+		col = strings.TrimSpace(col)
+		header[i] = col
+		fld, mapped := opts.ColMap[col]
+		if !mapped {
+			fld = col
+		}
+		switch {
+		case !mapped && col == opts.KeyCol:
+			if !opts.NoKey {
+				keyIdx = i
+			}
+		case fld == "":
+		case this.Flds[fld] == "":
+			return 0, []error{&CSVError{Line: 1, Col: col, Msg: "not a fld: " + fld}}
+		default:
+			flds[i] = fld
+		}
+	}
+
+	type row struct {
+		key  string
+		vals ValMap
+	}
+	var rows []row
+	var errs []error
+	keyLines := make(map[string]int) // line number of each key added
+	for {
+		cols, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if _, ok := err.(*csv.ParseError); !ok {
+				return 0, append(errs, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		line, _ := in.FieldPos(0)
+		if len(cols) > len(header) {
+			errs = append(errs, &CSVError{Line: line, Msg: fmt.Sprintf("%d columns, header has %d", len(cols), len(header))})
+			continue
+		}
+		rec := row{vals: make(ValMap)}
+		ok := true
+		for i, val := range cols {
+			if i == keyIdx {
+				rec.key = strings.TrimSpace(val)
+				continue
+			}
+			if flds[i] == "" || val == "" {
+				continue
+			}
+			converted, err := importVal(val, this.Flds[flds[i]], opts)
+			if err != nil {
+				errs = append(errs, &CSVError{Line: line, Col: header[i], Msg: err.Error()})
+				ok = false
+				continue
+			}
+			rec.vals[flds[i]] = converted
+		}
+		if keyIdx != -1 {
+			if msg := this.csvKeyError(rec.key, keyLines); msg != "" {
+				errs = append(errs, &CSVError{Line: line, Col: header[keyIdx], Msg: msg})
+				ok = false
+			} else if ok {
+				keyLines[rec.key] = line
+			}
+		}
+		if ok {
+			rows = append(rows, rec)
+		}
+	}
+
+	if this.RecMap == nil {
+		this.CreateRecMap()
+	}
+	var keys []string
+	if keyIdx == -1 && len(rows) > 0 {
+		keys = this.NewKeys(nil, len(rows))
+	}
+	for i, rec := range rows {
+		if keys != nil {
+			rec.key = keys[i]
+		}
+		this.AddRec(rec.key, rec.vals)
+	}
+	return len(rows), errs
+}
+
+// csvKeyError returns why key of an imported row cannot be added, "" if it can.
+func (this *Table) csvKeyError(key string, keyLines map[string]int) string {
+	if key == "" {
+		return "key required"
+	}
+	if strings.ContainsRune(key, '"') {
+		return fmt.Sprintf("key cannot contain quotes: %q", key)
+	}
+	if prev, found := keyLines[key]; found {
+		return fmt.Sprintf("duplicate key %s, also on line %d", key, prev)
+	}
+	if _, found := this.RecMap[key]; found {
+		return fmt.Sprintf("key %s already in RecMap", key)
+	}
+	return ""
+}
+
+// ConvertVal converts val to the stored form of valType, the same as ImportCSV with default
+// CSVOpts (ex. float " 0.50" is stored as "0.5"). Returns error if val is not a valid valType.
+func ConvertVal(val, valType string) (string, error) {
+	return importVal(val, valType, CSVOpts{}.defaults())
+}
+
+// importVal converts val from the file to the stored form of valType.
+func importVal(val, valType string, opts CSVOpts) (string, error) {
+	if strings.ContainsRune(val, '"') {
+		return "", fmt.Errorf("vals cannot contain quotes: %q", val)
+	}
+	trimmed := strings.TrimSpace(val)
+	switch valType {
+	case "int":
+		x, err := strconv.ParseInt(trimmed, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("not an int: %q", val)
+		}
+		return strconv.FormatInt(x, 10), nil
+	case "float":
+		x, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return "", fmt.Errorf("not a float: %q", val)
+		}
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	case "date":
+		t, err := time.Parse(opts.DateFormat, trimmed)
+		if err != nil {
+			return "", fmt.Errorf("not a date (%s): %q", opts.DateFormat, val)
+		}
+		return t.Format(DateFormat), nil
+	case "dateTime":
+		t, err := time.Parse(opts.DateTimeFormat, trimmed)
+		if err != nil {
+			return "", fmt.Errorf("not a dateTime (%s): %q", opts.DateTimeFormat, val)
+		}
+		return t.Format(DateTimeFormat), nil
+	case "bool":
+		x, err := strconv.ParseBool(trimmed)
+		if err != nil {
+			return "", fmt.Errorf("not a bool: %q", val)
+		}
+		return strconv.FormatBool(x), nil
+	case "bytes":
+		if _, err := base64.StdEncoding.DecodeString(trimmed); err != nil {
+			return "", fmt.Errorf("not base64: %q", val)
+		}
+		return trimmed, nil
+	}
+	return val, nil
+}
@@ -0,0 +1,83 @@
+// These tests check CSV export and import, using bucket "catalog".
+
+package bo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCSV(t *testing.T) {
+	CreateBucket("catalog")
+	flds := FldMap{"title": "str", "price": "float", "qty": "int", "added": "date", "active": "bool"}
+	catalog := NewTable(flds, NotShared, "catalog")
+	catalog.CreateRecMap()
+
+	data := `key,title,price,qty,added,active,notes
+k1,"Tea, green",4.50,10,03/02/2024,true,x
+k2,Coffee,12,-,03/02/2024,yes,y
+k3,Cocoa,abc,5,2024-03-02,1,z
+,Sugar,1,1,01/01/2024,false,
+k4,"Salt ""fine""",1,1,,,
+k5,Honey,,3,,FALSE,
+`
+	count, errs := catalog.ImportCSV(strings.NewReader(data), CSVOpts{DateFormat: "01/02/2006", ColMap: map[string]string{"notes": ""}})
+	if count != 2 || len(errs) != 6 {
+		t.Fatal("ImportCSV count or errs wrong: ", count, errs)
+	}
+	want := []string{
+		"line 3, column qty: not an int",
+		"line 3, column active: not a bool",
+		"line 4, column price: not a float",
+		"line 4, column added: not a date (01/02/2006)",
+		"line 5, column key: key required",
+		"line 6, column title: vals cannot contain quotes",
+	}
+	for i, err := range errs {
+		if !strings.HasPrefix(err.Error(), want[i]) {
+			t.Fatal("error wrong: ", err, ", want ", want[i])
+		}
+	}
+	k1 := catalog.GetRec("k1")
+	if k1.Get("title") != "Tea, green" || k1.Get("price") != "4.5" || k1.Get("added") != "2024-03-02" || !k1.GetBool("active") {
+		t.Fatal("imported vals wrong: ", k1.Vals)
+	}
+	if _, found := catalog.GetRec("k5").Vals["price"]; found || catalog.GetRec("k5").Get("active") != "false" {
+		t.Fatal("empty or bool val wrong: ", catalog.GetRec("k5").Vals)
+	}
+	tx := StartDBWrite()
+	catalog.Save(tx)
+	CommitDBWrite(tx)
+
+	count, errs = catalog.ImportCSV(strings.NewReader("key,qty\nk1,1\nk7,2\nk7,3\nk8,4\n"), CSVOpts{})
+	if count != 2 || len(errs) != 2 || errs[0].Error() != "line 2, column key: key k1 already in RecMap" ||
+		errs[1].Error() != "line 4, column key: duplicate key k7, also on line 3" || catalog.GetRec("k7").Get("qty") != "2" {
+		t.Fatal("ImportCSV duplicate keys wrong: ", count, errs)
+	}
+	delete(catalog.RecMap, "k7")
+	delete(catalog.RecMap, "k8")
+
+	_, errs = catalog.ImportCSV(strings.NewReader("key,color\nk9,red\n"), CSVOpts{})
+	if len(errs) != 1 || errs[0].Error() != "line 1, column color: not a fld: color" {
+		t.Fatal("unknown column error wrong: ", errs)
+	}
+
+	// no key column, keys from the table's key generator
+	count, errs = catalog.ImportCSV(strings.NewReader("Name;qty\nSalt;7\nPepper;2\n"), CSVOpts{Comma: ';', ColMap: map[string]string{"Name": "title"}})
+	if count != 2 || errs != nil || catalog.GetRec("00000002").Get("title") != "Pepper" {
+		t.Fatal("ImportCSV generated keys wrong: ", count, errs, catalog.RecMap)
+	}
+
+	var buf bytes.Buffer
+	catalog.CreateOrderBy("byQty", "qty")
+	err := catalog.ExportCSV(&buf, CSVOpts{OrderBy: "byQty", Flds: []string{"title", "qty", "added"}, DateFormat: "02.01.2006"})
+	wantCSV := "key,title,qty,added\n00000002,Pepper,2,\nk5,Honey,3,\n00000001,Salt,7,\nk1,\"Tea, green\",10,02.03.2024\n"
+	if err != nil || buf.String() != wantCSV {
+		t.Fatal("ExportCSV wrong:\n", buf.String(), err)
+	}
+	buf.Reset()
+	if err = catalog.ExportCSV(&buf, CSVOpts{NoKey: true}); err != nil || buf.String() != "active,added,price,qty,title\n,,,7,Salt\n,,,2,Pepper\ntrue,2024-03-02,4.5,10,\"Tea, green\"\nfalse,,,3,Honey\n" {
+		t.Fatal("ExportCSV default flds wrong:\n", buf.String(), err)
+	}
+}
@@ -0,0 +1,197 @@
+package bo
+
+import (
+	"fmt"
+	"github.com/boltdb/bolt"
+	"log"
+	"sort"
+	"strconv"
+)
+
+// Detail links a parent Table (ex. orders) with a child Table (ex. order details).
+// Child keys are the parent key followed by a zero prefixed line number, ex. "00000233" + "0005".
+// Parent keys should all be the same length, so one parent's key is never the prefix of another's.
+type Detail struct {
+	Parent    *Table
+	Child     *Table
+	LineWidth int // digits in line number, default 4
+}
+
+// NewDetail creates a Detail for parent and child tables.
+// If lineWidth is 0, 4 is used (line numbers "0001" to "9999").
+func NewDetail(parent, child *Table, lineWidth int) *Detail {
+	if lineWidth == 0 {
+		lineWidth = 4
+	}
+	if lineWidth < 1 || lineWidth > 18 {
+		log.Panic("NewDetail invalid lineWidth: ", lineWidth)
+	}
+	return &Detail{Parent: parent, Child: child, LineWidth: lineWidth}
+}
+
+// ChildKey returns the key of line lineNo of parentKey.
+func (this *Detail) ChildKey(parentKey string, lineNo int) string {
+	return parentKey + fmt.Sprintf("%0*d", this.LineWidth, lineNo)
+}
+
+// LineNo returns the line number part of childKey.
+func (this *Detail) LineNo(childKey string) int {
+	if len(childKey) < this.LineWidth {
+		log.Panic("LineNo invalid child key: ", childKey)
+	}
+	lineNo, err := strconv.Atoi(childKey[len(childKey)-this.LineWidth:])
+	if err != nil {
+		log.Panic("LineNo invalid child key: ", childKey)
+	}
+	return lineNo
+}
+
+// isChild returns true if key is a child key of parentKey.
+func (this *Detail) isChild(parentKey, key string) bool {
+	return len(key) == len(parentKey)+this.LineWidth && key[:len(parentKey)] == parentKey
+}
+
+// LoadChildren merges the parent rec and its child recs into the Parent and Child tables.
+// Recs of other parents already loaded are kept (see Table.MergePrefix).
+// Returns count of child recs read from db. Error (*MergeConflict) is only returned if a table's
+// MergePolicy is ConflictError, if the Child table has the conflict, the parent rec is already merged.
+func (this *Detail) LoadChildren(parentKey string) (int, error) {
+	if _, err := this.Parent.Merge1(parentKey); err != nil {
+		return 0, err
+	}
+	return this.Child.MergePrefix(parentKey)
+}
+
+// ChildKeys returns keys of parentKey's child recs in Child.RecMap, in line order.
+// Recs marked for deletion are not included.
+func (this *Detail) ChildKeys(parentKey string) []string {
+	this.Child.StartRead()
+	keys := make([]string, 0)
+	for key, rec := range this.Child.RecMap {
+		if this.isChild(parentKey, key) && rec.Vals["#delete"] != "1" {
+			keys = append(keys, key)
+		}
+	}
+	this.Child.EndRead()
+	sort.Strings(keys)
+	return keys
+}
+
+// LoopChildren executes fn for each child rec of parentKey in Child.RecMap, in line order.
+func (this *Detail) LoopChildren(parentKey string, fn func(key string, rec *Rec)) {
+	this.Child.loopKeys(fn, this.ChildKeys(parentKey))
+}
+
+// nextLineNo returns 1 + the highest line number of parentKey in Child.RecMap or the db.
+func (this *Detail) nextLineNo(parentKey string) int {
+	var last int
+	this.Child.StartRead()
+	for key := range this.Child.RecMap {
+		if this.isChild(parentKey, key) && this.LineNo(key) > last {
+			last = this.LineNo(key)
+		}
+	}
+	this.Child.EndRead()
+	prefix := bs(parentKey)
+	db.View(func(tx *bolt.Tx) error {
+		cursor := this.Child.openBucket(tx).Cursor()
+		var k []byte
+		if end := prefixEnd(prefix); end == nil {
+			k, _ = cursor.Last()
+		} else if k, _ = cursor.Seek(end); k != nil {
+			k, _ = cursor.Prev()
+		} else {
+			k, _ = cursor.Last()
+		}
+		for ; k != nil && len(k) >= len(prefix) && string(k[:len(prefix)]) == parentKey; k, _ = cursor.Prev() {
+			if this.isChild(parentKey, string(k)) {
+				if lineNo := this.LineNo(string(k)); lineNo > last {
+					last = lineNo
+				}
+				break
+			}
+		}
+		return nil
+	})
+	return last + 1
+}
+
+// AddChild adds a child rec for parentKey with the next line number and returns it.
+// The line number follows the highest line in Child.RecMap or the db,
+// so line numbers of deleted recs are not reused until Renumber is called.
+func (this *Detail) AddChild(parentKey string, valMap ...ValMap) *Rec {
+	lineNo := this.nextLineNo(parentKey)
+	if len(strconv.Itoa(lineNo)) > this.LineWidth {
+		log.Panic("AddChild line number exceeds LineWidth, parent key: ", parentKey)
+	}
+	return this.Child.AddRec(this.ChildKey(parentKey, lineNo), valMap...)
+}
+
+// Renumber changes line numbers of parentKey's child recs in Child.RecMap to 1, 2, 3 ...
+// keeping their current order. Recs moved to a new key are added, old keys no longer used
+// are marked for deletion. Changes are written to the db by Save.
+// Load children (LoadChildren) before calling, db recs not in RecMap are not renumbered.
+func (this *Detail) Renumber(parentKey string) {
+	keys := this.ChildKeys(parentKey)
+	moved := make(map[string]ValMap)
+	for i, key := range keys {
+		newKey := this.ChildKey(parentKey, i+1)
+		if newKey == key {
+			continue
+		}
+		vals := make(ValMap)
+		for fld, val := range this.Child.RecMap[key].Vals {
+			if fld[0] != '#' {
+				vals[fld] = val
+			}
+		}
+		moved[newKey] = vals
+	}
+	for key, rec := range this.Child.RecMap {
+		if !this.isChild(parentKey, key) || rec.Vals["#delete"] == "1" {
+			continue
+		}
+		if _, found := moved[key]; !found && this.LineNo(key) > len(keys) {
+			this.Child.DeleteRec(key)
+		}
+	}
+	for newKey, vals := range moved {
+		this.Child.AddRec(newKey, vals)
+	}
+}
+
+// DeleteParent marks the parent rec and all of its child recs for deletion.
+// Child recs in the db are loaded first, so they are deleted by Save.
+// Error (*MergeConflict) is only returned if a table's MergePolicy is ConflictError,
+// nothing is marked for deletion.
+func (this *Detail) DeleteParent(parentKey string) error {
+	if this.Parent.GetRec(parentKey) == nil {
+		this.Parent.Merge1(parentKey) // not in RecMap, cannot conflict
+	}
+	if _, err := this.Child.MergePrefix(parentKey); err != nil {
+		return err
+	}
+	if this.Parent.GetRec(parentKey) != nil {
+		this.Parent.DeleteRec(parentKey)
+	}
+	for _, key := range this.ChildKeys(parentKey) {
+		this.Child.DeleteRec(key)
+	}
+	return nil
+}
+
+// Save saves the Parent and Child tables in 1 transaction. Relations are checked for both
+// tables together, so a Restrict relation from Child to Parent does not stop DeleteParent,
+// and new child recs can reference a parent rec added in the same Save.
+// If a relation is violated, the transaction is rolled back and Save panics.
+// Returns count of parent and child recs written or deleted.
+func (this *Detail) Save() int {
+	tx := StartDBWrite()
+	count, err := saveTables(tx, this.Parent, this.Child)
+	if err != nil {
+		tx.Rollback()
+		log.Panic("Save failed, ", err)
+	}
+	CommitDBWrite(tx)
+	return count
+}
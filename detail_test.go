@@ -0,0 +1,111 @@
+// These tests check the Detail (parent/child) helper with "carts" and "cartItems" buckets.
+
+package bo
+
+import (
+	"testing"
+)
+
+func TestDetail(t *testing.T) {
+	CreateBucket("carts")
+	CreateBucket("cartItems")
+	carts := NewTable(FldMap{"customer": "str"}, NotShared, "carts")
+	carts.CreateRecMap()
+	items := NewTable(FldMap{"item": "str", "qty": "int"}, NotShared, "cartItems")
+	items.CreateRecMap()
+	detail := NewDetail(carts, items, 0)
+
+	carts.AddRec("c001", ValMap{"customer": "Ann"})
+	carts.AddRec("c002", ValMap{"customer": "Bob"})
+	for _, item := range []string{"pen", "ink", "pad"} {
+		detail.AddChild("c001", ValMap{"item": item, "qty": "1"})
+	}
+	detail.AddChild("c002", ValMap{"item": "cup", "qty": "2"})
+	if count := detail.Save(); count != 6 {
+		t.Fatal("Save count wrong: ", count)
+	}
+
+	items.CreateRecMap() // AddChild must find line numbers in db
+	if rec := detail.AddChild("c001", ValMap{"item": "tape"}); rec.Tbl != items || items.GetRec("c0010004") == nil {
+		t.Fatal("AddChild key wrong: ", items.RecMap)
+	}
+	delete(items.RecMap, "c0010004")
+
+	carts.CreateRecMap()
+	items.CreateRecMap()
+	if count, err := detail.LoadChildren("c001"); err != nil || count != 3 || carts.GetRec("c001") == nil {
+		t.Fatal("LoadChildren failed: ", count, err)
+	}
+	items.DeleteRec("c0010001")
+	detail.Renumber("c001")
+	keys := detail.ChildKeys("c001")
+	if len(keys) != 2 || keys[0] != "c0010001" || keys[1] != "c0010002" {
+		t.Fatal("Renumber keys wrong: ", keys)
+	}
+	if items.GetRec("c0010001").Get("item") != "ink" || items.GetRec("c0010002").Get("item") != "pad" {
+		t.Fatal("Renumber vals wrong")
+	}
+	detail.Save()
+	items.Load()
+	if len(items.RecMap) != 3 || items.GetRec("c0010003") != nil {
+		t.Fatal("renumbered recs not saved: ", items.OrderBy["byKey"])
+	}
+
+	carts.CreateRecMap()
+	items.CreateRecMap()
+	if err := detail.DeleteParent("c001"); err != nil {
+		t.Fatal(err)
+	}
+	detail.Save()
+	carts.Load()
+	items.Load()
+	if len(carts.RecMap) != 1 || len(items.RecMap) != 1 || items.GetRec("c0020001") == nil {
+		t.Fatal("DeleteParent failed: ", items.OrderBy["byKey"])
+	}
+
+	var lines []string
+	detail.LoopChildren("c002", func(key string, rec *Rec) {
+		lines = append(lines, rec.Get("item"))
+	})
+	if len(lines) != 1 || lines[0] != "cup" {
+		t.Fatal("LoopChildren wrong: ", lines)
+	}
+}
+
+func TestDetailRelation(t *testing.T) {
+	CreateBucket("baskets")
+	CreateBucket("basketItems")
+	defer ClearRelations()
+	AddRelation(Relation{BktPath: []string{"basketItems"}, Fld: "basketId", TargetPath: []string{"baskets"}}) // Restrict
+	baskets := NewTable(FldMap{"owner": "str"}, NotShared, "baskets")
+	baskets.CreateRecMap()
+	items := NewTable(FldMap{"basketId": "str", "item": "str"}, NotShared, "basketItems")
+	items.CreateRecMap()
+	detail := NewDetail(baskets, items, 0)
+
+	baskets.AddRec("b1", ValMap{"owner": "Ann"})
+	detail.AddChild("b1", ValMap{"basketId": "b1", "item": "egg"})
+	detail.AddChild("b1", ValMap{"basketId": "b1", "item": "jam"})
+	if count := detail.Save(); count != 3 { // children reference parent added in same Save
+		t.Fatal("Save count wrong: ", count)
+	}
+
+	baskets.DeleteRec("b1")
+	tx := StartDBWrite()
+	if _, err := baskets.TrySave(tx); err == nil {
+		t.Fatal("Restrict relation did not stop parent delete")
+	}
+	tx.Rollback()
+
+	baskets.CreateRecMap()
+	items.CreateRecMap()
+	if err := detail.DeleteParent("b1"); err != nil {
+		t.Fatal(err)
+	}
+	if count := detail.Save(); count != 3 {
+		t.Fatal("DeleteParent Save count wrong: ", count)
+	}
+	if baskets.Load() != 0 || items.Load() != 0 {
+		t.Fatal("DeleteParent recs not deleted")
+	}
+}
@@ -0,0 +1,212 @@
+package bo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/boltdb/bolt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Import modes, see Import.
+const (
+	ImportReplace = "replace"      // imported rec replaces existing rec with same key
+	ImportMerge   = "merge"        // imported flds are merged into existing rec
+	ImportSkip    = "skipExisting" // existing rec is not changed
+)
+
+// ImportBatchSize is the number of lines written per transaction by Import.
+var ImportBatchSize = 1000
+
+// jsonLine is 1 line of Export / Import.
+// Rec lines have Key and Vals, bucket lines (1 per bucket, before its recs) have Seq.
+// Bkt is the path of a nested bucket, relative to the exported bucket (omitted for its own recs).
+type jsonLine struct {
+	Bkt  []string `json:"bkt,omitempty"`
+	Seq  *uint64  `json:"seq,omitempty"`
+	Key  string   `json:"key,omitempty"`
+	Vals ValMap   `json:"vals,omitempty"`
+}
+
+// Export writes bucket bktPath as JSON Lines, 1 object per rec: {"key":"00001","vals":{"name":"Ann"}}.
+// Nested buckets and sequence values are included (see readme). All lines are written from
+// 1 read transaction. Returns count of recs written, error if the bucket does not exist.
+func Export(bktPath []string, w io.Writer) (int, error) {
+	var count int
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	if len(bktPath) == 0 {
+		return 0, fmt.Errorf("Export bucket path required")
+	}
+	err := db.View(func(tx *bolt.Tx) error {
+		var bkt *bolt.Bucket
+		if parent := openParent(tx, bktPath, false); parent != nil {
+			bkt = parent.Bucket(bs(bktPath[len(bktPath)-1]))
+		}
+		if bkt == nil {
+			return fmt.Errorf("Export bucket not found: %v", bktPath)
+		}
+		return exportBucket(bkt, nil, enc, &count)
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, buf.Flush()
+}
+
+// exportBucket writes the bucket line of bkt (at relative path), its recs, then its nested buckets.
+func exportBucket(bkt *bolt.Bucket, path []string, enc *json.Encoder, count *int) error {
+	seq := bkt.Sequence()
+	if err := enc.Encode(jsonLine{Bkt: path, Seq: &seq}); err != nil {
+		return err
+	}
+	nested := make([]string, 0)
+	cursor := bkt.Cursor()
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		if v == nil {
+			nested = append(nested, string(k))
+			continue
+		}
+		vals := make(ValMap)
+		vals.fromJson(v)
+		if err := enc.Encode(jsonLine{Bkt: path, Key: string(k), Vals: vals}); err != nil {
+			return err
+		}
+		*count++
+	}
+	for _, name := range nested {
+		child := append(append([]string(nil), path...), name)
+		if err := exportBucket(bkt.Bucket(bs(name)), child, enc, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportResult is returned by Import.
+type ImportResult struct {
+	Added   int // recs with new keys
+	Updated int // existing recs replaced or merged
+	Skipped int // existing recs not changed (ImportSkip)
+}
+
+// Import reads JSON Lines written by Export into bucket bktPath, which is created if missing
+// (nested buckets too). Lines are written in transactions of ImportBatchSize lines, if an error
+// occurs, batches already written are not rolled back. Mode determines what happens to existing
+// recs (ImportReplace, ImportMerge, ImportSkip). Bucket sequences are set to the imported value
+// if it is higher. Relations are not checked. Keys, fld names and vals containing quotes (")
+// cannot be stored by Bo, the line is returned as an error.
+func Import(bktPath []string, r io.Reader, mode string) (ImportResult, error) {
+	var result ImportResult
+	if mode != ImportReplace && mode != ImportMerge && mode != ImportSkip {
+		log.Panic("Import invalid mode: ", mode)
+	}
+	dec := json.NewDecoder(bufio.NewReader(r))
+	batch := make([]jsonLine, 0, ImportBatchSize)
+	lineNo := 0
+	for {
+		var line jsonLine
+		err := dec.Decode(&line)
+		if err == nil {
+			lineNo++
+			if line.Key == "" && line.Seq == nil {
+				return result, fmt.Errorf("import line %d: key or seq required", lineNo)
+			}
+			if err := line.validate(); err != nil {
+				return result, fmt.Errorf("import line %d: %v", lineNo, err)
+			}
+			batch = append(batch, line)
+		}
+		if len(batch) > 0 && (len(batch) == ImportBatchSize || err != nil) {
+			if updErr := db.Update(func(tx *bolt.Tx) error {
+				return importBatch(tx, bktPath, batch, mode, &result)
+			}); updErr != nil {
+				return result, fmt.Errorf("import batch ending line %d: %v", lineNo, updErr)
+			}
+			batch = batch[:0]
+		}
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, fmt.Errorf("import line %d: %v", lineNo+1, err)
+		}
+	}
+}
+
+// validate returns error if line contains a value that cannot be stored.
+// Stored recs are not escaped (see ValMap.toJson), so keys, fld names and vals cannot contain quotes.
+func (this jsonLine) validate() error {
+	if strings.ContainsRune(this.Key, '"') {
+		return fmt.Errorf("key cannot contain quotes: %q", this.Key)
+	}
+	for fld, val := range this.Vals {
+		if strings.ContainsRune(fld, '"') {
+			return fmt.Errorf("fld name cannot contain quotes: %q", fld)
+		}
+		if strings.ContainsRune(val, '"') {
+			return fmt.Errorf("fld %s val cannot contain quotes: %q", fld, val)
+		}
+	}
+	for _, name := range this.Bkt {
+		if name == "" {
+			return fmt.Errorf("empty bucket name in bkt: %q", this.Bkt)
+		}
+	}
+	return nil
+}
+
+// importBatch writes lines in tx. Result is only updated if all lines are written.
+func importBatch(tx *bolt.Tx, bktPath []string, lines []jsonLine, mode string, result *ImportResult) error {
+	counts := *result
+	for _, line := range lines {
+		path := append(append([]string(nil), bktPath...), line.Bkt...)
+		parent := openParent(tx, path, true)
+		if parent == nil {
+			return fmt.Errorf("cannot create bucket %v", path)
+		}
+		bkt := parent.Bucket(bs(path[len(path)-1]))
+		if bkt == nil {
+			var err error
+			if bkt, err = parent.CreateBucket(bs(path[len(path)-1])); err != nil {
+				return err
+			}
+		}
+		if line.Seq != nil {
+			if *line.Seq > bkt.Sequence() {
+				if err := bkt.SetSequence(*line.Seq); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		vals := line.Vals
+		if vals == nil {
+			vals = make(ValMap)
+		}
+		if old := bkt.Get(bs(line.Key)); old != nil {
+			switch mode {
+			case ImportSkip:
+				counts.Skipped++
+				continue
+			case ImportMerge:
+				merged := make(ValMap)
+				merged.fromJson(old)
+				for fld, val := range vals {
+					merged[fld] = val
+				}
+				vals = merged
+			}
+			counts.Updated++
+		} else {
+			counts.Added++
+		}
+		if err := bkt.Put(bs(line.Key), vals.toJson()); err != nil {
+			return err
+		}
+	}
+	*result = counts
+	return nil
+}
@@ -0,0 +1,87 @@
+// These tests check Export and Import, using buckets "depot" and "depot2".
+
+package bo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	CreateBucketIfNotExists("depot", "parts", "bins")
+	parts := NewTable(FldMap{"name": "str", "qty": "int"}, NotShared, "depot", "parts")
+	parts.CreateRecMap()
+	parts.AddRec("p1", ValMap{"name": "bolt m6", "qty": "10"})
+	parts.AddRec("p2", ValMap{"name": "nut", "qty": "25"})
+	bins := NewTable(FldMap{"loc": "str"}, NotShared, "depot", "parts", "bins")
+	bins.CreateRecMap()
+	bins.AddRec("b1", ValMap{"loc": "A1"})
+	tx := StartDBWrite()
+	parts.Save(tx)
+	bins.Save(tx)
+	CommitDBWrite(tx)
+	parts.GetNextKeys(7)
+
+	var buf bytes.Buffer
+	count, err := Export([]string{"depot", "parts"}, &buf)
+	if err != nil || count != 3 {
+		t.Fatal("Export wrong: ", count, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || lines[0] != `{"seq":7}` || lines[1] != `{"key":"p1","vals":{"name":"bolt m6","qty":"10"}}` ||
+		lines[3] != `{"bkt":["bins"],"seq":0}` || lines[4] != `{"bkt":["bins"],"key":"b1","vals":{"loc":"A1"}}` {
+		t.Fatal("Export lines wrong:\n", buf.String())
+	}
+
+	ImportBatchSize = 2 // several transactions
+	defer func() { ImportBatchSize = 1000 }()
+	result, err := Import([]string{"depot2", "parts"}, bytes.NewReader(buf.Bytes()), ImportReplace)
+	if err != nil || result.Added != 3 || result.Updated != 0 {
+		t.Fatal("Import wrong: ", result, err)
+	}
+	copied := NewTable(FldMap{"name": "str", "qty": "int"}, NotShared, "depot2", "parts")
+	if copied.Load1("p2") != 1 || copied.GetNextKey() != "00000008" {
+		t.Fatal("imported rec or sequence wrong")
+	}
+	copiedBins := NewTable(FldMap{"loc": "str"}, NotShared, "depot2", "parts", "bins")
+	if copiedBins.Load1("b1") != 1 || copiedBins.GetRec("b1").Get("loc") != "A1" {
+		t.Fatal("imported nested bucket wrong")
+	}
+
+	update := `{"key":"p1","vals":{"qty":"3"}}` + "\n" + `{"key":"p3","vals":{"name":"washer"}}` + "\n"
+	result, err = Import([]string{"depot2", "parts"}, strings.NewReader(update), ImportSkip)
+	if err != nil || result.Added != 1 || result.Skipped != 1 {
+		t.Fatal("Import skipExisting wrong: ", result, err)
+	}
+	copied.Load1("p1")
+	if copied.GetRec("p1").Get("qty") != "10" {
+		t.Fatal("skipExisting changed rec")
+	}
+	result, err = Import([]string{"depot2", "parts"}, strings.NewReader(update), ImportMerge)
+	if err != nil || result.Updated != 2 {
+		t.Fatal("Import merge wrong: ", result, err)
+	}
+	copied.Load1("p1")
+	if copied.GetRec("p1").Get("qty") != "3" || copied.GetRec("p1").Get("name") != "bolt m6" {
+		t.Fatal("merge wrong: ", copied.GetRec("p1"))
+	}
+	result, err = Import([]string{"depot2", "parts"}, strings.NewReader(update), ImportReplace)
+	copied.Load1("p1")
+	if err != nil || result.Updated != 2 || copied.GetRec("p1").Get("name") != "" {
+		t.Fatal("replace wrong: ", copied.GetRec("p1"))
+	}
+
+	quoted := `{"key":"p5","vals":{"qty":"1"}}` + "\n" + `{"key":"p6","vals":{"name":"a\"b"}}` + "\n"
+	if _, err = Import([]string{"depot2", "parts"}, strings.NewReader(quoted), ImportReplace); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatal("quoted val error wrong: ", err)
+	}
+	if _, err = Export([]string{"depot", "nosuch"}, &buf); err == nil {
+		t.Fatal("Export missing bucket did not return error")
+	}
+
+	bad := `{"key":"p4","vals":{"qty":"1"}}` + "\n" + `{"key":` + "\n"
+	if _, err = Import([]string{"depot2", "parts"}, strings.NewReader(bad), ImportReplace); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatal("bad line error wrong: ", err)
+	}
+}
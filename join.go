@@ -0,0 +1,219 @@
+package bo
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// Join types, see Table.Join.
+const (
+	InnerJoin = "inner" // recs without a matching rec in the other table are skipped
+	LeftJoin  = "left"  // all recs are included, values of missing other rec are empty
+)
+
+type join struct {
+	name     string // used to qualify fld names, last name in other table's BktPath
+	tbl      *Table
+	localFld string // fld containing key of other table's rec
+	joinType string
+}
+
+// JoinView combines recs of a Table with matching recs of other Tables.
+type JoinView struct {
+	Tbl   *Table
+	joins []join
+}
+
+// JoinRec is a rec of a JoinView's Table plus the matching recs of joined Tables.
+// Get methods accept fld names qualified with a table name, ex. "customers.name".
+// The table name is the last name in the table's BktPath.
+// Unqualified fld names refer to the JoinView's Table.
+type JoinRec struct {
+	Key  string
+	recs map[string]*Rec // key is table name, "" is JoinView's Table
+}
+
+// Join returns a JoinView combining this table's recs with recs in other,
+// where localFld contains the key of the rec in other.
+// joinType is InnerJoin or LeftJoin.
+// Recs are not read until JoinView.Loop is called.
+// Ex: sales.Join(customers, "custId", bo.LeftJoin).Loop(fn) - in fn, jrec.Get("customers.name")
+func (this *Table) Join(other *Table, localFld, joinType string) *JoinView {
+	view := &JoinView{Tbl: this}
+	return view.Join(other, localFld, joinType)
+}
+
+// Join adds another table to the JoinView. localFld can be a fld of a previously joined table.
+func (this *JoinView) Join(other *Table, localFld, joinType string) *JoinView {
+	if joinType != InnerJoin && joinType != LeftJoin {
+		log.Panic("invalid joinType: ", joinType)
+	}
+	if len(other.BktPath) == 0 {
+		log.Panic("Join table has no BktPath")
+	}
+	this.joins = append(this.joins, join{
+		name:     other.BktPath[len(other.BktPath)-1],
+		tbl:      other,
+		localFld: localFld,
+		joinType: joinType,
+	})
+	return this
+}
+
+// Loop calls fn for each rec in the JoinView's Table, like Table.Loop.
+// Keys of joined recs not in the other table's RecMap are loaded first using MergeSome.
+// For InnerJoin, recs without a matching rec are skipped.
+func (this *JoinView) Loop(fn func(key string, jrec *JoinRec), orderBy ...string) {
+	this.loadMissing()
+	this.Tbl.Loop(func(key string, rec *Rec) {
+		if jrec := this.joinRec(key, rec); jrec != nil {
+			fn(key, jrec)
+		}
+	}, orderBy...)
+}
+
+// loadMissing loads recs of joined tables needed by the JoinView, 1 MergeSome per joined table.
+func (this *JoinView) loadMissing() {
+	for i, jn := range this.joins {
+		partial := &JoinView{Tbl: this.Tbl, joins: this.joins[:i]}
+		missing := make([]string, 0)
+		found := make(map[string]bool)
+		for key, rec := range this.Tbl.RecMap {
+			jrec := partial.joinRec(key, rec)
+			if jrec == nil {
+				continue
+			}
+			otherKey := jrec.Get(jn.localFld)
+			if otherKey == "" || found[otherKey] {
+				continue
+			}
+			found[otherKey] = true
+			if jn.tbl.GetRec(otherKey) == nil {
+				missing = append(missing, otherKey)
+			}
+		}
+		if len(missing) > 0 {
+			jn.tbl.MergeSome(missing) // keys are not in RecMap, cannot conflict
+		}
+	}
+}
+
+// joinRec returns JoinRec for rec, nil if an InnerJoin has no matching rec.
+func (this *JoinView) joinRec(key string, rec *Rec) *JoinRec {
+	jrec := &JoinRec{Key: key, recs: make(map[string]*Rec, len(this.joins)+2)}
+	jrec.recs[""] = rec
+	if len(this.Tbl.BktPath) > 0 {
+		jrec.recs[this.Tbl.BktPath[len(this.Tbl.BktPath)-1]] = rec
+	}
+	for _, jn := range this.joins {
+		var other *Rec
+		if otherKey := jrec.Get(jn.localFld); otherKey != "" && jn.tbl.RecMap != nil {
+			other = jn.tbl.RecMap[otherKey]
+		}
+		if other == nil && jn.joinType == InnerJoin {
+			return nil
+		}
+		jrec.recs[jn.name] = other
+	}
+	return jrec
+}
+
+// Rec returns rec of table with tblName, nil if LeftJoin had no matching rec.
+// Empty tblName returns rec of JoinView's Table.
+func (this *JoinRec) Rec(tblName string) *Rec {
+	rec, found := this.recs[tblName]
+	if !found {
+		log.Panic("JoinRec invalid table name: ", tblName)
+	}
+	return rec
+}
+
+// resolve returns rec and unqualified fld name for a possibly qualified fld name.
+func (this *JoinRec) resolve(fld string) (*Rec, string) {
+	if ndx := strings.Index(fld, "."); ndx > 0 {
+		if rec, found := this.recs[fld[:ndx]]; found {
+			return rec, fld[ndx+1:]
+		}
+	}
+	return this.recs[""], fld
+}
+
+// Get returns string value for fld. If rec has no value for fld (or LeftJoin had no match),
+// optional defaultVal or "" is returned.
+func (this *JoinRec) Get(fld string, defaultVal ...string) string {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return ""
+	}
+	return rec.Get(fld, defaultVal...)
+}
+
+func (this *JoinRec) GetInt(fld string, defaultVal ...int64) int64 {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return 0
+	}
+	return rec.GetInt(fld, defaultVal...)
+}
+
+func (this *JoinRec) GetFloat(fld string, defaultVal ...float64) float64 {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return 0
+	}
+	return rec.GetFloat(fld, defaultVal...)
+}
+
+func (this *JoinRec) GetDate(fld string, defaultVal ...time.Time) time.Time {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return ZeroDate
+	}
+	return rec.GetDate(fld, defaultVal...)
+}
+
+func (this *JoinRec) GetDateTime(fld string, defaultVal ...time.Time) time.Time {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return ZeroDate
+	}
+	return rec.GetDateTime(fld, defaultVal...)
+}
+
+func (this *JoinRec) GetBytes(fld string, defaultVal ...[]byte) []byte {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return make([]byte, 0)
+	}
+	return rec.GetBytes(fld, defaultVal...)
+}
+
+func (this *JoinRec) GetBool(fld string, defaultVal ...bool) bool {
+	rec, fld := this.resolve(fld)
+	if rec == nil {
+		if len(defaultVal) > 0 {
+			return defaultVal[0]
+		}
+		return false
+	}
+	return rec.GetBool(fld, defaultVal...)
+}
@@ -0,0 +1,67 @@
+// These tests join invoices to clients and regions.
+
+package bo
+
+import (
+	"testing"
+)
+
+func TestJoin(t *testing.T) {
+	CreateBucket("regions")
+	CreateBucket("clients")
+	CreateBucket("invoices")
+	regionFlds := FldMap{"name": "str"}
+	clientFlds := FldMap{"name": "str", "regionId": "str"}
+	invoiceFlds := FldMap{"clientId": "str", "amt": "float"}
+
+	tx := StartDBWrite()
+	regions := NewTable(regionFlds, NotShared, "regions")
+	regions.CreateRecMap()
+	regions.AddRec("r1", ValMap{"name": "north"})
+	regions.Save(tx)
+	clients := NewTable(clientFlds, NotShared, "clients")
+	clients.CreateRecMap()
+	clients.AddRec("c1", ValMap{"name": "Lanco", "regionId": "r1"})
+	clients.AddRec("c2", ValMap{"name": "Neely", "regionId": "r9"})
+	clients.Save(tx)
+	invoices := NewTable(invoiceFlds, NotShared, "invoices")
+	invoices.CreateRecMap()
+	invoices.AddRec("i1", ValMap{"clientId": "c1", "amt": "10"})
+	invoices.AddRec("i2", ValMap{"clientId": "c2", "amt": "20"})
+	invoices.AddRec("i3", ValMap{"clientId": "c9", "amt": "30"})
+	invoices.Save(tx)
+	CommitDBWrite(tx)
+
+	invoices.Load()
+	clients = NewTable(clientFlds, NotShared, "clients") // clients are loaded by Join
+	clients.Load1("c2")                                  // c1 will be loaded lazily
+	regions = NewTable(regionFlds, NotShared, "regions") // RecMap not created
+	result := make(map[string]string)
+	invoices.Join(clients, "clientId", LeftJoin).Join(regions, "clients.regionId", InnerJoin).Loop(
+		func(key string, jrec *JoinRec) {
+			result[key] = jrec.Get("clients.name") + "/" + jrec.Get("regions.name")
+			if jrec.GetFloat("amt") != jrec.Rec("invoices").GetFloat("amt") {
+				t.Fatal("JoinRec unqualified fld wrong")
+			}
+		}, "byKey")
+	if len(result) != 1 || result["i1"] != "Lanco/north" {
+		t.Fatal("inner join wrong: ", result)
+	}
+	if len(clients.RecMap) != 2 || len(regions.RecMap) != 1 {
+		t.Fatal("Join did not load missing recs")
+	}
+
+	count := 0
+	invoices.Join(clients, "clientId", LeftJoin).Loop(func(key string, jrec *JoinRec) {
+		count++
+		if key == "i3" && (jrec.Get("clients.name", "none") != "none" || jrec.Rec("clients") != nil) {
+			t.Fatal("left join without match wrong")
+		}
+		if key == "i3" && (!jrec.GetDateTime("clients.name").Equal(ZeroDate) || len(jrec.GetBytes("clients.name")) != 0) {
+			t.Fatal("left join without match GetDateTime or GetBytes wrong")
+		}
+	})
+	if count != 3 {
+		t.Fatal("left join count wrong: ", count)
+	}
+}
@@ -0,0 +1,166 @@
+package bo
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Order preserving key encodings. Keys created by these funcs sort (as strings) in the
+// same order as the values they encode, so they can be used with LoadRange, Scan, etc.
+
+const signBit = 1 << 63
+
+// IntToKey returns x as a 16 character hex key, negative values sort before positive.
+func IntToKey(x int64) string {
+	return fmt.Sprintf("%016x", uint64(x)^signBit)
+}
+
+// KeyToInt returns the int encoded by IntToKey.
+func KeyToInt(key string) int64 {
+	u, err := strconv.ParseUint(key, 16, 64)
+	if err != nil || len(key) != 16 {
+		log.Panic("KeyToInt invalid key: ", key)
+	}
+	return int64(u ^ signBit)
+}
+
+// FloatToKey returns x as a 16 character hex key, negative values sort before positive.
+func FloatToKey(x float64) string {
+	bits := math.Float64bits(x)
+	if bits&signBit != 0 {
+		bits = ^bits
+	} else {
+		bits |= signBit
+	}
+	return fmt.Sprintf("%016x", bits)
+}
+
+// KeyToFloat returns the float encoded by FloatToKey.
+func KeyToFloat(key string) float64 {
+	bits, err := strconv.ParseUint(key, 16, 64)
+	if err != nil || len(key) != 16 {
+		log.Panic("KeyToFloat invalid key: ", key)
+	}
+	if bits&signBit != 0 {
+		bits &^= signBit
+	} else {
+		bits = ^bits
+	}
+	return math.Float64frombits(bits)
+}
+
+// DateToKey returns date part of t as an 8 character key "yyyymmdd".
+func DateToKey(t time.Time) string {
+	return t.Format(keyDateFormat)
+}
+
+// KeyToDate returns the date encoded by DateToKey.
+func KeyToDate(key string) time.Time {
+	t, err := time.Parse(keyDateFormat, key)
+	if err != nil {
+		log.Panic("KeyToDate invalid key: ", key)
+	}
+	return t
+}
+
+// DateTimeToKey returns t (to the second) as a 14 character key "yyyymmddhhmmss".
+func DateTimeToKey(t time.Time) string {
+	return t.Format(keyDateTimeFormat)
+}
+
+// KeyToDateTime returns the date time encoded by DateTimeToKey.
+func KeyToDateTime(key string) time.Time {
+	t, err := time.Parse(keyDateTimeFormat, key)
+	if err != nil {
+		log.Panic("KeyToDateTime invalid key: ", key)
+	}
+	return t
+}
+
+// tuple string parts end with strEnd, zero bytes inside strings are escaped,
+// so a shorter string sorts before a longer one beginning with the same characters
+const (
+	strEnd  = "\x00\x01"
+	strZero = "\x00\xff"
+)
+
+// TupleToKey returns a key made from vals, sorting by the 1st val, then the 2nd, etc.
+// Vals can be string, int, int64, float64 or time.Time (encoded with DateTimeToKey).
+// Strings can be any length, other vals use the encodings above.
+func TupleToKey(vals ...interface{}) string {
+	var key strings.Builder
+	for _, val := range vals {
+		switch v := val.(type) {
+		case string:
+			key.WriteString(strings.Replace(v, "\x00", strZero, -1))
+			key.WriteString(strEnd)
+		case int:
+			key.WriteString(IntToKey(int64(v)))
+		case int64:
+			key.WriteString(IntToKey(v))
+		case float64:
+			key.WriteString(FloatToKey(v))
+		case time.Time:
+			key.WriteString(DateTimeToKey(v))
+		default:
+			log.Panic("TupleToKey invalid val type: ", val)
+		}
+	}
+	return key.String()
+}
+
+// KeyToTuple returns the vals of a key created by TupleToKey.
+// Types are the val types in order: "str", "int", "float", "date", "dateTime".
+// Returned vals are string, int64, float64 or time.Time. Use "date" for times encoded
+// with DateToKey (ex. a tuple key built by concatenating keys).
+func KeyToTuple(key string, types ...string) []interface{} {
+	vals := make([]interface{}, len(types))
+	pos := 0
+	next := func(size int) string {
+		if pos+size > len(key) {
+			log.Panic("KeyToTuple key too short: ", key)
+		}
+		pos += size
+		return key[pos-size : pos]
+	}
+	for i, valType := range types {
+		switch valType {
+		case "str":
+			var s strings.Builder
+			for {
+				x := strings.IndexByte(key[pos:], 0)
+				if x == -1 || pos+x+1 >= len(key) {
+					log.Panic("KeyToTuple invalid str: ", key)
+				}
+				s.WriteString(key[pos : pos+x])
+				pos += x + 2
+				if key[pos-1] == strEnd[1] {
+					break
+				}
+				if key[pos-1] != strZero[1] {
+					log.Panic("KeyToTuple invalid str: ", key)
+				}
+				s.WriteByte(0) // escaped zero byte
+			}
+			vals[i] = s.String()
+		case "int":
+			vals[i] = KeyToInt(next(16))
+		case "float":
+			vals[i] = KeyToFloat(next(16))
+		case "date":
+			vals[i] = KeyToDate(next(len(keyDateFormat)))
+		case "dateTime":
+			vals[i] = KeyToDateTime(next(len(keyDateTimeFormat)))
+		default:
+			log.Panic("KeyToTuple invalid type: ", valType)
+		}
+	}
+	if pos != len(key) {
+		log.Panic("KeyToTuple key has extra characters: ", key)
+	}
+	return vals
+}
@@ -0,0 +1,67 @@
+// These tests check order preserving key encodings and the KeySize overflow check,
+// using the "readings" bucket.
+
+package bo
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestKeyEncodings(t *testing.T) {
+	ints := []int64{math.MinInt64, -1000, -1, 0, 1, 42, math.MaxInt64}
+	floats := []float64{math.Inf(-1), -1e10, -2.5, -0.001, 0, 0.001, 3.25, 1e300, math.Inf(1)}
+	intKeys := make([]string, len(ints))
+	for i, x := range ints {
+		intKeys[i] = IntToKey(x)
+		if KeyToInt(intKeys[i]) != x {
+			t.Fatal("KeyToInt wrong: ", x)
+		}
+	}
+	floatKeys := make([]string, len(floats))
+	for i, x := range floats {
+		floatKeys[i] = FloatToKey(x)
+		if KeyToFloat(floatKeys[i]) != x {
+			t.Fatal("KeyToFloat wrong: ", x)
+		}
+	}
+	if !sort.StringsAreSorted(intKeys) || !sort.StringsAreSorted(floatKeys) {
+		t.Fatal("int or float keys not in order")
+	}
+
+	day := time.Date(2024, 2, 29, 13, 5, 9, 0, time.UTC)
+	tuples := []string{
+		TupleToKey("a", int64(-5), day),
+		TupleToKey("a", 3, day),
+		TupleToKey("a\x00", -9, day),
+		TupleToKey("ab", -9, day),
+		TupleToKey("b", -9, day),
+	}
+	if !sort.StringsAreSorted(tuples) {
+		t.Fatal("tuple keys not in order")
+	}
+	vals := KeyToTuple(tuples[2], "str", "int", "dateTime")
+	if vals[0].(string) != "a\x00" || vals[1].(int64) != -9 || !vals[2].(time.Time).Equal(day) {
+		t.Fatal("KeyToTuple wrong: ", vals)
+	}
+	if KeyToDate(DateToKey(day)).Format(DateFormat) != "2024-02-29" {
+		t.Fatal("DateToKey wrong")
+	}
+
+	CreateBucket("readings")
+	readings := NewTable(FldMap{"val": "float"}, NotShared, "readings")
+	readings.SetKeySize(2)
+	if keys := readings.GetNextKeys(99); keys[98] != "99" {
+		t.Fatal("GetNextKeys wrong: ", keys[98])
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("key exceeding KeySize did not panic")
+			}
+		}()
+		readings.GetNextKey()
+	}()
+}
@@ -0,0 +1,229 @@
+package bo
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"github.com/boltdb/bolt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyGenerator creates new rec keys for a Table, see Table.NewKey.
+// Tx is the caller's open transaction or nil. Generators that need the db use tx if it is
+// writable, so they can be used between StartDBWrite and CommitDBWrite.
+type KeyGenerator interface {
+	NextKey(tx *bolt.Tx, tbl *Table) string
+}
+
+// SetKeyGenerator sets the KeyGenerator used by NewKey and NewKeys.
+func (this *Table) SetKeyGenerator(keyGen KeyGenerator) {
+	this.KeyGen = keyGen
+}
+
+// NewKey returns a new key from the Table's KeyGen (SequenceKeys if not set).
+// Pass the open write transaction if there is one, else nil.
+func (this *Table) NewKey(tx *bolt.Tx) string {
+	if this.KeyGen == nil {
+		return SequenceKeys{}.NextKey(tx, this)
+	}
+	return this.KeyGen.NextKey(tx, this)
+}
+
+// NewKeys returns count new keys, see NewKey.
+func (this *Table) NewKeys(tx *bolt.Tx, count int) []string {
+	if tx == nil && this.KeyGen == nil {
+		return this.GetNextKeys(count) // 1 write transaction for all keys
+	}
+	keys := make([]string, count)
+	for i := range keys {
+		keys[i] = this.NewKey(tx)
+	}
+	return keys
+}
+
+// writeTx runs fn in tx if it is writable, else in a new write transaction.
+func writeTx(tx *bolt.Tx, fn func(tx *bolt.Tx) error) {
+	var err error
+	if tx == nil {
+		err = db.Update(fn)
+	} else if tx.Writable() {
+		err = fn(tx)
+	} else {
+		log.Panic("key generator requires a write transaction")
+	}
+	if err != nil {
+		log.Panic("key generator failed, ", err)
+	}
+}
+
+// SequenceKeys generates keys from the bucket's NextSequence, formatted with Table.KeySize
+// (same as GetNextKey). If tx is rolled back, its sequence values are reused.
+type SequenceKeys struct{}
+
+func (this SequenceKeys) NextKey(tx *bolt.Tx, tbl *Table) string {
+	var key string
+	writeTx(tx, func(tx *bolt.Tx) error {
+		nextKey, err := tbl.openBucket(tx).NextSequence()
+		if err != nil {
+			return err
+		}
+		key, err = tbl.formatKey(nextKey)
+		return err
+	})
+	return key
+}
+
+// BlockKeys generates sequence keys like SequenceKeys, but reserves BlockSize sequence values
+// at a time, then hands them out from memory without locking or db writes.
+// Keys are unique, but not always in the order they are used, and unused values of a block
+// are lost when the program ends. A BlockKeys should only be used with 1 bucket.
+// If the transaction passed to NextKey when a block was reserved is rolled back,
+// call Reset, else keys of that block may be generated again.
+type BlockKeys struct {
+	BlockSize uint64
+	lock      sync.Mutex
+	block     atomic.Value // *keyBlock
+}
+
+type keyBlock struct {
+	next uint64 // last value handed out, updated atomically
+	end  uint64 // last value of block
+}
+
+// NewBlockKeys creates a BlockKeys reserving blockSize keys at a time.
+func NewBlockKeys(blockSize int) *BlockKeys {
+	if blockSize < 1 {
+		log.Panic("NewBlockKeys invalid blockSize: ", blockSize)
+	}
+	return &BlockKeys{BlockSize: uint64(blockSize)}
+}
+
+func (this *BlockKeys) NextKey(tx *bolt.Tx, tbl *Table) string {
+	for {
+		block, _ := this.block.Load().(*keyBlock)
+		if block != nil {
+			if n := atomic.AddUint64(&block.next, 1); n <= block.end {
+				key, err := tbl.formatKey(n)
+				if err != nil {
+					log.Panic("BlockKeys failed, ", err)
+				}
+				return key
+			}
+		}
+		this.reserve(tx, tbl, block)
+	}
+}
+
+// reserve replaces block (used up) with a new block, unless another goroutine already has.
+func (this *BlockKeys) reserve(tx *bolt.Tx, tbl *Table, block *keyBlock) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if current, _ := this.block.Load().(*keyBlock); current != block {
+		return
+	}
+	var start uint64
+	writeTx(tx, func(tx *bolt.Tx) error {
+		bkt := tbl.openBucket(tx)
+		start = bkt.Sequence()
+		return bkt.SetSequence(start + this.BlockSize)
+	})
+	this.block.Store(&keyBlock{next: start, end: start + this.BlockSize})
+}
+
+// Reset discards the current block, the next key comes from a new block.
+func (this *BlockKeys) Reset() {
+	this.lock.Lock()
+	this.block.Store(&keyBlock{})
+	this.lock.Unlock()
+}
+
+// randomBytes fills b with random bytes.
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		log.Panic("random key failed, ", err)
+	}
+}
+
+// UUIDv4Keys generates random UUID (version 4) keys, ex. "f47ac10b-58cc-4372-a567-0e02b2c3d479".
+type UUIDv4Keys struct{}
+
+func (this UUIDv4Keys) NextKey(tx *bolt.Tx, tbl *Table) string {
+	var uuid [16]byte
+	randomBytes(uuid[:])
+	uuid[6] = uuid[6]&0x0f | 0x40 // version 4
+	uuid[8] = uuid[8]&0x3f | 0x80 // variant
+	return formatUUID(uuid)
+}
+
+// UUIDv7Keys generates time ordered UUID (version 7) keys, 48 bit millisecond timestamp
+// followed by random bits. Keys created in the same millisecond are in random order.
+type UUIDv7Keys struct{}
+
+func (this UUIDv7Keys) NextKey(tx *bolt.Tx, tbl *Table) string {
+	var uuid [16]byte
+	randomBytes(uuid[6:])
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint16(uuid[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(uuid[2:6], uint32(ms))
+	uuid[6] = uuid[6]&0x0f | 0x70 // version 7
+	uuid[8] = uuid[8]&0x3f | 0x80 // variant
+	return formatUUID(uuid)
+}
+
+func formatUUID(uuid [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ" // ULID base32 alphabet
+
+// ULIDKeys generates ULID keys, 26 characters, ex. "01HRZ3NDEKTSV4RRFFQ69G5FAV".
+// A 48 bit millisecond timestamp is followed by 80 random bits. Keys created by the same
+// ULIDKeys in the same millisecond increment the random bits, so keys are always in order.
+// Use 1 ULIDKeys (ex. a package var) for all Tables needing ordered keys.
+type ULIDKeys struct {
+	lock   sync.Mutex
+	lastMs uint64
+	last   [10]byte // random part of last key
+}
+
+func (this *ULIDKeys) NextKey(tx *bolt.Tx, tbl *Table) string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	if ms <= this.lastMs { // same millisecond (or clock moved back), increment random part
+		ms = this.lastMs
+		for i := len(this.last) - 1; i >= 0; i-- {
+			this.last[i]++
+			if this.last[i] != 0 {
+				break
+			}
+			if i == 0 {
+				log.Panic("ULID random part overflow")
+			}
+		}
+	} else {
+		randomBytes(this.last[:])
+		this.lastMs = ms
+	}
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], this.last[:])
+	return encodeULID(id)
+}
+
+// encodeULID returns the 128 bits of id as 26 base32 characters (the 1st has 3 bits).
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[0:8])
+	lo := binary.BigEndian.Uint64(id[8:16])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
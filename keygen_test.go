@@ -0,0 +1,69 @@
+// These tests check key generators, using the "tickets" bucket.
+
+package bo
+
+import (
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestKeyGenerators(t *testing.T) {
+	CreateBucket("tickets")
+	tickets := NewTable(FldMap{"desc": "str"}, NotShared, "tickets")
+	tickets.SetKeySize(5)
+	tickets.CreateRecMap()
+
+	tx := StartDBWrite() // sequence keys inside an open write transaction
+	key1 := tickets.NewKey(tx)
+	keys := tickets.NewKeys(tx, 2)
+	tickets.AddRec(key1, ValMap{"desc": "a"})
+	tickets.Save(tx)
+	CommitDBWrite(tx)
+	if key1 != "00001" || keys[0] != "00002" || keys[1] != "00003" {
+		t.Fatal("sequence keys wrong: ", key1, keys)
+	}
+
+	blocks := NewBlockKeys(10)
+	tickets.SetKeyGenerator(blocks)
+	var lock sync.Mutex
+	seen := make(map[string]bool)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				key := tickets.NewKey(nil)
+				lock.Lock()
+				seen[key] = true
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 100 || !seen["00004"] || !seen["00103"] {
+		t.Fatal("block keys not unique or out of range: ", len(seen))
+	}
+	seq := SequenceKeys{}
+	if key := seq.NextKey(nil, tickets); key != "00104" {
+		t.Fatal("block keys did not reserve sequence: ", key)
+	}
+
+	ulids := new(ULIDKeys)
+	tickets.SetKeyGenerator(ulids)
+	ids := tickets.NewKeys(nil, 1000)
+	if len(ids[0]) != 26 || !sort.StringsAreSorted(ids) || ids[0] == ids[1] {
+		t.Fatal("ULID keys not in order: ", ids[0], ids[1])
+	}
+
+	tickets.SetKeyGenerator(UUIDv4Keys{})
+	id := tickets.NewKey(nil)
+	if len(id) != 36 || id[14] != '4' || id == tickets.NewKey(nil) {
+		t.Fatal("UUIDv4 key wrong: ", id)
+	}
+	tickets.SetKeyGenerator(UUIDv7Keys{})
+	if id = tickets.NewKey(nil); len(id) != 36 || id[14] != '7' {
+		t.Fatal("UUIDv7 key wrong: ", id)
+	}
+}
@@ -0,0 +1,231 @@
+package bo
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyPart types, see KeyPart.
+const (
+	KeyStr      = "str"      // fixed width string, padded on right with spaces
+	KeyInt      = "int"      // zero prefixed int, must not be negative
+	KeyDate     = "date"     // date as yyyymmdd
+	KeyDateTime = "dateTime" // date time as yyyymmddhhmmss
+	KeyRevTime  = "revTime"  // timestamp (nanoseconds) stored so newest sorts first
+)
+
+// key formats of date types, chosen so keys sort in time order regardless of DateFormat
+const (
+	keyDateFormat     = "20060102"
+	keyDateTimeFormat = "20060102150405"
+	revTimeWidth      = 19
+)
+
+// KeyPart describes 1 part of a composite key.
+// Width is required for KeyStr and KeyInt, it is fixed for other types.
+type KeyPart struct {
+	Name  string
+	Type  string
+	Width int
+}
+
+// KeySchema describes keys composed of multiple parts, ex. custId + saleDate + saleId.
+// Every part has a fixed width, so keys built by a KeySchema sort in part order
+// and can be split back into parts.
+type KeySchema struct {
+	Parts []KeyPart
+	size  int
+}
+
+// NewKeySchema creates a KeySchema from parts, in key order.
+func NewKeySchema(parts ...KeyPart) *KeySchema {
+	schema := &KeySchema{Parts: make([]KeyPart, len(parts))}
+	names := make(map[string]bool)
+	for i, part := range parts {
+		switch part.Type {
+		case KeyStr, KeyInt:
+			if part.Width < 1 {
+				log.Panic("NewKeySchema Width required for part: ", part.Name)
+			}
+		case KeyDate:
+			part.Width = len(keyDateFormat)
+		case KeyDateTime:
+			part.Width = len(keyDateTimeFormat)
+		case KeyRevTime:
+			part.Width = revTimeWidth
+		default:
+			log.Panic("NewKeySchema invalid type: ", part.Type, ", part: ", part.Name)
+		}
+		if part.Name == "" || names[part.Name] {
+			log.Panic("NewKeySchema part name missing or duplicate: ", part.Name)
+		}
+		names[part.Name] = true
+		schema.Parts[i] = part
+		schema.size += part.Width
+	}
+	return schema
+}
+
+// Size returns the length of keys built by this schema.
+func (this *KeySchema) Size() int {
+	return this.size
+}
+
+// Build returns a key made from vals, 1 val per part in order.
+// Vals can be strings or:
+//   - KeyInt: int, int64
+//   - KeyDate, KeyDateTime, KeyRevTime: time.Time
+//
+// Date strings use DateFormat or DateTimeFormat, KeyRevTime strings can also use time.RFC3339Nano.
+func (this *KeySchema) Build(vals ...interface{}) string {
+	if len(vals) != len(this.Parts) {
+		log.Panic("KeySchema.Build expects ", len(this.Parts), " vals, received ", len(vals))
+	}
+	return this.build(vals)
+}
+
+// build returns the key prefix made from vals for the first len(vals) parts.
+func (this *KeySchema) build(vals []interface{}) string {
+	if len(vals) > len(this.Parts) {
+		log.Panic("KeySchema has ", len(this.Parts), " parts, received ", len(vals), " vals")
+	}
+	var key strings.Builder
+	for i, val := range vals {
+		key.WriteString(this.Parts[i].format(val))
+	}
+	return key.String()
+}
+
+// format returns val as a key part string of exactly Width characters.
+func (this KeyPart) format(val interface{}) string {
+	switch this.Type {
+	case KeyStr:
+		s, ok := val.(string)
+		if !ok || len(s) > this.Width {
+			log.Panic("key part ", this.Name, " invalid val: ", val)
+		}
+		return s + strings.Repeat(" ", this.Width-len(s))
+	case KeyInt:
+		var n int64
+		switch v := val.(type) {
+		case int:
+			n = int64(v)
+		case int64:
+			n = v
+		case string:
+			n = StrToInt(v)
+		default:
+			log.Panic("key part ", this.Name, " invalid val: ", val)
+		}
+		s := strconv.FormatInt(n, 10)
+		if n < 0 || len(s) > this.Width {
+			log.Panic("key part ", this.Name, " val out of range: ", val)
+		}
+		return strings.Repeat("0", this.Width-len(s)) + s
+	case KeyDate:
+		return this.timeVal(val, DateFormat).Format(keyDateFormat)
+	case KeyDateTime:
+		return this.timeVal(val, DateTimeFormat).Format(keyDateTimeFormat)
+	case KeyRevTime:
+		nanos := this.timeVal(val, DateTimeFormat).UnixNano()
+		if nanos < 0 {
+			log.Panic("key part ", this.Name, " time before 1970: ", val)
+		}
+		return fmt.Sprintf("%019d", math.MaxInt64-nanos)
+	}
+	return ""
+}
+
+// timeVal returns val as a time.Time, string vals are parsed with layout (or RFC3339Nano).
+func (this KeyPart) timeVal(val interface{}, layout string) time.Time {
+	switch v := val.(type) {
+	case time.Time:
+		return v
+	case string:
+		if t, err := time.Parse(layout, v); err == nil {
+			return t
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	}
+	log.Panic("key part ", this.Name, " invalid date val: ", val)
+	return time.Time{}
+}
+
+// Parse splits key into its parts, returning a map of part name to string val.
+// Strings are returned without padding, ints without leading zeros,
+// dates in DateFormat and DateTimeFormat, KeyRevTime in time.RFC3339Nano (UTC).
+func (this *KeySchema) Parse(key string) map[string]string {
+	if len(key) != this.size {
+		log.Panic("KeySchema.Parse key size is not ", this.size, ": ", key)
+	}
+	vals := make(map[string]string, len(this.Parts))
+	var pos int
+	for _, part := range this.Parts {
+		vals[part.Name] = part.parse(key[pos : pos+part.Width])
+		pos += part.Width
+	}
+	return vals
+}
+
+// Get returns the val of part name in key, see Parse.
+func (this *KeySchema) Get(key, name string) string {
+	var pos int
+	for _, part := range this.Parts {
+		if part.Name == name {
+			if len(key) < pos+part.Width {
+				log.Panic("KeySchema.Get key too short: ", key)
+			}
+			return part.parse(key[pos : pos+part.Width])
+		}
+		pos += part.Width
+	}
+	log.Panic("KeySchema.Get invalid part name: ", name)
+	return ""
+}
+
+// parse returns the val of key part s.
+func (this KeyPart) parse(s string) string {
+	switch this.Type {
+	case KeyStr:
+		return strings.TrimRight(s, " ")
+	case KeyInt:
+		return IntToStr(StrToInt(s))
+	case KeyDate:
+		t, err := time.Parse(keyDateFormat, s)
+		if err != nil {
+			log.Panic("key part ", this.Name, " invalid date: ", s)
+		}
+		return t.Format(DateFormat)
+	case KeyDateTime:
+		t, err := time.Parse(keyDateTimeFormat, s)
+		if err != nil {
+			log.Panic("key part ", this.Name, " invalid date time: ", s)
+		}
+		return t.Format(DateTimeFormat)
+	case KeyRevTime:
+		return time.Unix(0, math.MaxInt64-StrToInt(s)).UTC().Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+// Prefix returns the key prefix made from vals for the first len(vals) parts.
+// Use with LoadPrefix, MergePrefix, SetPagePrefix, ScanOpts.Prefix.
+func (this *KeySchema) Prefix(vals ...interface{}) string {
+	return this.build(vals)
+}
+
+// Range returns start and end keys including all keys from the prefix made from startVals
+// to the prefix made from endVals (both inclusive). Use with LoadRange, MergeRange, SetPageRange.
+//
+//	tbl.LoadRange(schema.Range([]interface{}{"C001", jan1}, []interface{}{"C001", jan31}))
+func (this *KeySchema) Range(startVals, endVals []interface{}) (string, string) {
+	start := this.build(startVals)
+	end := this.build(endVals)
+	return start, end + strings.Repeat("\xff", this.size-len(end))
+}
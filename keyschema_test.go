@@ -0,0 +1,72 @@
+// These tests check KeySchema building and parsing keys, using the "shipments" bucket.
+
+package bo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeySchema(t *testing.T) {
+	schema := NewKeySchema(
+		KeyPart{Name: "cust", Type: KeyStr, Width: 4},
+		KeyPart{Name: "date", Type: KeyDate},
+		KeyPart{Name: "seq", Type: KeyInt, Width: 3},
+	)
+	if schema.Size() != 15 {
+		t.Fatal("Size wrong: ", schema.Size())
+	}
+	key := schema.Build("C1", "2024-03-05", 7)
+	if key != "C1  20240305007" {
+		t.Fatal("Build wrong: ", key)
+	}
+	vals := schema.Parse(key)
+	if vals["cust"] != "C1" || vals["date"] != "2024-03-05" || vals["seq"] != "7" {
+		t.Fatal("Parse wrong: ", vals)
+	}
+	if schema.Get(key, "seq") != "7" || schema.Prefix("C1") != "C1  " {
+		t.Fatal("Get or Prefix wrong")
+	}
+
+	CreateBucket("shipments")
+	shipments := NewTable(FldMap{"qty": "int"}, NotShared, "shipments")
+	shipments.CreateRecMap()
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 60; i++ {
+		shipments.AddRec(schema.Build("C1", jan.AddDate(0, 0, i), i), ValMap{"qty": IntToStr(int64(i))})
+		shipments.AddRec(schema.Build("C10", jan.AddDate(0, 0, i), i), ValMap{"qty": IntToStr(int64(i))})
+	}
+	tx := StartDBWrite()
+	shipments.Save(tx)
+	CommitDBWrite(tx)
+
+	if count := shipments.LoadPrefix(schema.Prefix("C1")); count != 60 {
+		t.Fatal("LoadPrefix count wrong: ", count)
+	}
+	feb1 := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	feb29 := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	count := shipments.LoadRange(schema.Range([]interface{}{"C10", feb1}, []interface{}{"C10", feb29}))
+	if count != 29 || schema.Get(shipments.OrderBy["byKey"][0], "date") != "2024-02-01" {
+		t.Fatal("LoadRange wrong: ", count)
+	}
+
+	events := NewKeySchema(KeyPart{Name: "dev", Type: KeyStr, Width: 2}, KeyPart{Name: "ts", Type: KeyRevTime})
+	t1 := time.Date(2024, 5, 1, 10, 0, 0, 123, time.UTC)
+	k1 := events.Build("d1", t1)
+	k2 := events.Build("d1", t1.Add(time.Second))
+	if k2 >= k1 {
+		t.Fatal("KeyRevTime newest does not sort first")
+	}
+	if ts := events.Get(k1, "ts"); ts != t1.Format(time.RFC3339Nano) || events.Build("d1", ts) != k1 {
+		t.Fatal("KeyRevTime parse wrong: ", ts)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("oversize key part did not panic")
+			}
+		}()
+		schema.Build("C12345", jan, 1)
+	}()
+}
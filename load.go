@@ -0,0 +1,206 @@
+package bo
+
+import (
+	"fmt"
+	"github.com/boltdb/bolt"
+	"sort"
+	"sync"
+)
+
+// MergePolicy values, see Table.MergePolicy.
+const (
+	KeepLocal     = "keepLocal" // keep the RecMap version (default)
+	Overwrite     = "overwrite" // replace with the db version, unsaved changes are lost
+	ConflictError = "error"     // nothing is merged, Merge method returns *MergeConflict
+)
+
+// MergeConflict is returned by Merge methods if MergePolicy is ConflictError and recs being
+// loaded have unsaved changes in RecMap. RecMap is not changed.
+type MergeConflict struct {
+	BktPath []string
+	Keys    []string // keys of recs with unsaved changes, in key order
+}
+
+func (this *MergeConflict) Error() string {
+	return fmt.Sprintf("merge conflict, recs have unsaved changes, bkt: %v, keys: %v", this.BktPath, this.Keys)
+}
+
+// selection describes the db recs read by a Load or Merge method.
+type selection struct {
+	kind   string   // all, some, range, prefix
+	keys   []string // used by kind some
+	bounds ScanOpts // used by kinds range, prefix
+}
+
+// read calls fn for every db rec in bkt matching the selection, in key order (except kind some).
+func (this selection) read(bkt *bolt.Bucket, fn func(k, v []byte)) {
+	if this.kind == "some" {
+		for _, key := range this.keys {
+			if v := bkt.Get(bs(key)); v != nil {
+				fn(bs(key), v)
+			}
+		}
+		return
+	}
+	iter := &Iterator{opts: this.bounds, cursor: bkt.Cursor()}
+	for k, v := iter.first(); k != nil && iter.inBounds(k); k, v = iter.cursor.Next() {
+		fn(k, v)
+	}
+}
+
+// load reads recs in sel into RecMap.
+// If merge is false, RecMap and OrderBy are recreated.
+// If merge is true, recs are added to RecMap (see MergePolicy) and OrderBy["byKey"] is rebuilt.
+// Error is only returned by merge with policy ConflictError, RecMap is then unchanged.
+func (this *Table) load(sel selection, merge bool) (int, error) {
+	this.StartWrite()
+	defer this.EndWrite()
+	if merge && this.RecMap != nil && this.MergePolicy == ConflictError {
+		if conflicts := this.mergeConflicts(sel); len(conflicts) > 0 {
+			return 0, &MergeConflict{BktPath: this.BktPath, Keys: conflicts}
+		}
+	}
+	if !merge || this.RecMap == nil {
+		this.RecMap = make(map[string]*Rec)
+		this.OrderBy = make(map[string][]string)
+		this.sels = nil
+	}
+	this.sels = append(this.sels, sel)
+	keys := make([]string, 0, 100)
+	var count int
+	db.View(func(tx *bolt.Tx) error {
+		bkt := this.openBucket(tx)
+		if this.loadWorkers > 1 {
+			recKeys, recs := this.decodeParallel(bkt, sel)
+			for i, key := range recKeys {
+				count++
+				if merge && !this.mergeRec(key) {
+					continue
+				}
+				this.RecMap[key] = recs[i]
+				keys = append(keys, key)
+			}
+			return nil
+		}
+		sel.read(bkt, func(k, v []byte) {
+			key := string(k)
+			count++
+			if merge && !this.mergeRec(key) {
+				return
+			}
+			this.RecMap[key] = this.loadedRec(key, v)
+			keys = append(keys, key) // Bolt returns keys in sorted order
+		})
+		return nil
+	})
+	if merge {
+		keys = make([]string, 0, len(this.RecMap))
+		for key := range this.RecMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		this.OrderBy["byKey"] = keys
+	} else if sel.kind != "some" {
+		this.OrderBy["byKey"] = keys
+	}
+	this.rebuildLiveOrders()
+	if merge {
+		return count, nil
+	}
+	return len(this.RecMap), nil
+}
+
+// mergeConflicts returns keys in sel of recs in RecMap with unsaved changes.
+func (this *Table) mergeConflicts(sel selection) []string {
+	conflicts := make([]string, 0)
+	db.View(func(tx *bolt.Tx) error {
+		sel.read(this.openBucket(tx), func(k, v []byte) {
+			if rec, found := this.RecMap[string(k)]; found && (rec.Vals["#c"] == "1" || rec.Vals["#delete"] == "1") {
+				conflicts = append(conflicts, string(k))
+			}
+		})
+		return nil
+	})
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// decodeParallel reads recs in sel from bkt, decoding them with loadWorkers goroutines.
+// The keys are split into loadWorkers contiguous ranges, 1 per goroutine.
+// Returned keys and recs are in the same order as read by sel.
+// Db values are only valid during the read transaction, so all workers finish before returning.
+func (this *Table) decodeParallel(bkt *bolt.Bucket, sel selection) ([]string, []*Rec) {
+	type kv struct {
+		k, v []byte
+	}
+	pairs := make([]kv, 0, 1000)
+	sel.read(bkt, func(k, v []byte) {
+		pairs = append(pairs, kv{k, v})
+	})
+	keys := make([]string, len(pairs))
+	recs := make([]*Rec, len(pairs))
+	rangeSize := (len(pairs) + this.loadWorkers - 1) / this.loadWorkers
+	var wait sync.WaitGroup
+	for start := 0; start < len(pairs); start += rangeSize {
+		end := start + rangeSize
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+		wait.Add(1)
+		go func(start, end int) {
+			defer wait.Done()
+			for i := start; i < end; i++ {
+				keys[i] = string(pairs[i].k)
+				recs[i] = this.loadedRec(keys[i], pairs[i].v)
+			}
+		}(start, end)
+	}
+	wait.Wait()
+	return keys, recs
+}
+
+// SetLoadWorkers sets number of goroutines used to decode recs by Load and Merge methods.
+// Values less than 2 decode on the calling goroutine (default).
+// Decoding in parallel is faster for large selections if multiple cpus are available.
+func (this *Table) SetLoadWorkers(workers int) {
+	this.loadWorkers = workers
+}
+
+// mergeRec returns true if the db version of rec with key should be put in RecMap.
+func (this *Table) mergeRec(key string) bool {
+	rec, found := this.RecMap[key]
+	if !found || (rec.Vals["#c"] != "1" && rec.Vals["#delete"] != "1") {
+		return true
+	}
+	return this.MergePolicy == Overwrite // ConflictError is handled before merging
+}
+
+// MergeLoad is the merge version of Load.
+// Recs are added to RecMap instead of recreating it. Unchanged recs already in RecMap
+// are replaced with the db version. See Table.MergePolicy for recs with unsaved changes.
+// OrderBy["byKey"] contains all RecMap keys in order, other OrderBy entries are not changed.
+// Returns count of recs read from db. Error (*MergeConflict) is only returned if MergePolicy is
+// ConflictError, nothing is merged.
+func (this *Table) MergeLoad() (int, error) {
+	return this.load(selection{kind: "all"}, true)
+}
+
+// Merge1 is the merge version of Load1, see MergeLoad.
+func (this *Table) Merge1(key string) (int, error) {
+	return this.load(selection{kind: "some", keys: []string{key}}, true)
+}
+
+// MergeSome is the merge version of LoadSome, see MergeLoad.
+func (this *Table) MergeSome(keys []string) (int, error) {
+	return this.load(selection{kind: "some", keys: keys}, true)
+}
+
+// MergeRange is the merge version of LoadRange, see MergeLoad.
+func (this *Table) MergeRange(start, end string) (int, error) {
+	return this.load(selection{kind: "range", bounds: ScanOpts{Start: start, End: end}}, true)
+}
+
+// MergePrefix is the merge version of LoadPrefix, see MergeLoad.
+func (this *Table) MergePrefix(prefix string) (int, error) {
+	return this.load(selection{kind: "prefix", bounds: ScanOpts{Prefix: prefix}}, true)
+}
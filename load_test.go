@@ -0,0 +1,61 @@
+// These tests merge recs from several loads into 1 table.
+
+package bo
+
+import (
+	"testing"
+)
+
+var orderFlds = FldMap{
+	"custId": "str",
+	"amt":    "float",
+}
+
+func TestMergeLoad(t *testing.T) {
+	CreateBucket("orders")
+	tbl := NewTable(orderFlds, NotShared, "orders")
+	tbl.CreateRecMap()
+	for _, key := range []string{"c1-01", "c1-02", "c2-01", "c3-01", "c3-02"} {
+		tbl.AddRec(key, ValMap{"custId": key[:2], "amt": "10"})
+	}
+	tx := StartDBWrite()
+	tbl.Save(tx)
+	CommitDBWrite(tx)
+
+	tbl = NewTable(orderFlds, NotShared, "orders")
+	tbl.LoadPrefix("c3")
+	tbl.MergePrefix("c1")
+	tbl.MergeSome([]string{"c2-01", "c9-99"})
+	keys := tbl.OrderBy["byKey"]
+	if len(tbl.RecMap) != 5 || len(keys) != 5 || keys[0] != "c1-01" || keys[4] != "c3-02" {
+		t.Fatal("merge load wrong: ", keys)
+	}
+
+	tbl.GetRec("c1-01").SetFloat("amt", 99)
+	tbl.MergePrefix("c1") // default policy KeepLocal
+	if tbl.GetRec("c1-01").GetFloat("amt") != 99 {
+		t.Fatal("KeepLocal policy lost local change")
+	}
+	tbl.MergePolicy = Overwrite
+	tbl.MergePrefix("c1")
+	if tbl.GetRec("c1-01").GetFloat("amt") != 10 {
+		t.Fatal("Overwrite policy kept local change")
+	}
+
+	tbl.GetRec("c1-01").SetFloat("amt", 99)
+	tbl.AddRec("c1-03", ValMap{"custId": "c1", "amt": "5"})
+	tbl.GetRec("c2-01").Set("amt", "1")
+	tbl.MergePolicy = ConflictError
+	tbl.RecMap["c1-02"].Vals["amt"] = "7" // not marked changed, would be replaced by a merge
+	count, err := tbl.MergePrefix("c1")
+	conflict, ok := err.(*MergeConflict)
+	if count != 0 || !ok || len(conflict.Keys) != 1 || conflict.Keys[0] != "c1-01" {
+		t.Fatal("ConflictError policy wrong: ", count, err)
+	}
+	if tbl.GetRec("c1-02").Get("amt") != "7" || tbl.GetRec("c1-01").GetFloat("amt") != 99 {
+		t.Fatal("ConflictError merged recs")
+	}
+	if count, err = tbl.MergePrefix("c3"); count != 2 || err != nil {
+		t.Fatal("merge without conflicts wrong: ", count, err)
+	}
+}
@@ -0,0 +1,45 @@
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"log"
+)
+
+// MetaBktName is the name of the root bucket where Bo keeps its own data (ex. named sequences).
+// A Namespace has its own metadata bucket with the same name inside its root bucket.
+var MetaBktName = "_bo"
+
+// isMetaBkt returns true if bucket name is a metadata bucket, which is not shown by
+// ListBuckets, Walk and Stats.
+func isMetaBkt(name []byte) bool {
+	return string(name) == MetaBktName
+}
+
+// metaBucket returns the nested bucket names inside the metadata bucket.
+// Missing buckets are created if tx is writable, else nil is returned if any are missing.
+func metaBucket(tx *bolt.Tx, names ...string) *bolt.Bucket {
+	return rootMetaBucket(tx, nil, names...)
+}
+
+// rootMetaBucket is metaBucket for the metadata bucket inside bucket root (top level if root is nil).
+func rootMetaBucket(tx *bolt.Tx, root []string, names ...string) *bolt.Bucket {
+	var parent bucketParent = tx
+	if len(root) > 0 {
+		parent = OpenBucket(tx, root)
+	}
+	var bkt *bolt.Bucket
+	for _, name := range append([]string{MetaBktName}, names...) {
+		bkt = parent.Bucket(bs(name))
+		if bkt == nil && tx.Writable() {
+			var err error
+			if bkt, err = parent.CreateBucket(bs(name)); err != nil {
+				log.Panic("metaBucket create failed, ", err, root, names)
+			}
+		}
+		if bkt == nil {
+			return nil
+		}
+		parent = bkt
+	}
+	return bkt
+}
@@ -0,0 +1,140 @@
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"log"
+	"os"
+)
+
+// Namespace keeps all buckets of 1 tenant (client) inside a root bucket.
+// Bucket paths passed to Namespace methods are relative to Root.
+// Tables created by NewTable panic if their BktPath is changed to a path outside Root.
+type Namespace struct {
+	Root []string
+}
+
+// NewNamespace returns a Namespace with root bucket path root (ex. "tenants", "acme").
+// The root bucket is not created, see Create.
+func NewNamespace(root ...string) *Namespace {
+	if len(root) == 0 {
+		log.Panic("NewNamespace root required")
+	}
+	return &Namespace{Root: root}
+}
+
+// Path returns the full path of bktPath (Root followed by bktPath).
+func (this *Namespace) Path(bktPath ...string) []string {
+	return append(append([]string(nil), this.Root...), bktPath...)
+}
+
+// Create creates the root bucket (and any missing buckets above it) if it does not exist.
+func (this *Namespace) Create() {
+	CreateBucketIfNotExists(this.Root...)
+}
+
+// Exists returns true if the root bucket exists.
+func (this *Namespace) Exists() bool {
+	return BucketExists(this.Root...)
+}
+
+// Delete deletes the root bucket and everything in it.
+func (this *Namespace) Delete() {
+	DeleteBucket(this.Root...)
+}
+
+// Copy copies everything in the namespace, including sequences, to a new namespace with root newRoot.
+func (this *Namespace) Copy(newRoot ...string) *Namespace {
+	CopyBucket(this.Root, newRoot)
+	return NewNamespace(newRoot...)
+}
+
+// Export writes the namespace to a new bolt database file, using the same root path.
+// The file must not exist.
+func (this *Namespace) Export(fileName string) {
+	exportDB(fileName, this.Root)
+}
+
+// exportDB copies bucket bktPath to a new bolt database file (with the same path).
+func exportDB(fileName string, bktPath []string) {
+	if fileExists(fileName) {
+		log.Panic("export file already exists: ", fileName)
+	}
+	out, err := bolt.Open(fileName, 0600, nil)
+	if err != nil {
+		log.Panic("export open failed, ", err)
+	}
+	defer out.Close()
+	err = db.View(func(tx *bolt.Tx) error {
+		src := OpenBucket(tx, bktPath)
+		return out.Update(func(outTx *bolt.Tx) error {
+			dst := openParent(outTx, bktPath, true)
+			return copyBucket(src, dst, bs(bktPath[len(bktPath)-1]))
+		})
+	})
+	if err != nil {
+		log.Panic("export failed, ", err)
+	}
+}
+
+// fileExists returns true if fileName exists.
+func fileExists(fileName string) bool {
+	_, err := os.Stat(fileName)
+	return err == nil
+}
+
+// NewTable is NewTable with bktPath inside the namespace.
+// The Table panics if its BktPath is changed to a path outside the namespace.
+func (this *Namespace) NewTable(flds FldMap, shared bool, bktPath ...string) *Table {
+	tbl := NewTable(flds, shared, this.Path(bktPath...)...)
+	tbl.nsRoot = this.Root
+	return tbl
+}
+
+// CreateBucket is CreateBucket with bktPath inside the namespace.
+func (this *Namespace) CreateBucket(bktPath ...string) *bolt.Bucket {
+	return CreateBucket(this.Path(bktPath...)...)
+}
+
+// CreateBucketIfNotExists is CreateBucketIfNotExists with bktPath inside the namespace.
+func (this *Namespace) CreateBucketIfNotExists(bktPath ...string) {
+	CreateBucketIfNotExists(this.Path(bktPath...)...)
+}
+
+// BucketExists is BucketExists with bktPath inside the namespace.
+func (this *Namespace) BucketExists(bktPath ...string) bool {
+	return BucketExists(this.Path(bktPath...)...)
+}
+
+// DeleteBucket is DeleteBucket with bktPath inside the namespace.
+func (this *Namespace) DeleteBucket(bktPath ...string) {
+	if len(bktPath) == 0 {
+		log.Panic("Namespace.DeleteBucket bktPath required, use Delete to delete namespace")
+	}
+	DeleteBucket(this.Path(bktPath...)...)
+}
+
+// ListBuckets is ListBuckets with bktPath inside the namespace (root bucket if none).
+func (this *Namespace) ListBuckets(bktPath ...string) []BucketInfo {
+	return ListBuckets(this.Path(bktPath...)...)
+}
+
+// DefineSeq is DefineSeq with values stored in the namespace's own metadata bucket,
+// so each namespace has separate values and they are copied, exported and deleted with it.
+func (this *Namespace) DefineSeq(name string, opts SeqOpts) *NamedSeq {
+	seq := DefineSeq(name, opts)
+	seq.root = this.Root
+	return seq
+}
+
+// AddRelation is AddRelation with BktPath and TargetPath inside the namespace.
+func (this *Namespace) AddRelation(rel Relation) {
+	rel.BktPath = this.Path(rel.BktPath...)
+	rel.TargetPath = this.Path(rel.TargetPath...)
+	AddRelation(rel)
+}
+
+// RegisterSchema is RegisterSchema with the schema stored in the namespace's own metadata bucket,
+// so it is copied, exported and deleted with the namespace. GetSchema finds it by full path.
+func (this *Namespace) RegisterSchema(flds FldMap, bktPath ...string) {
+	registerSchema(this.Root, flds, bktPath)
+}
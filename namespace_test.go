@@ -0,0 +1,104 @@
+// These tests check tenant namespaces, using buckets under "tenants".
+
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNamespace(t *testing.T) {
+	acme := NewNamespace("tenants", "acme")
+	acme.Create()
+	acme.CreateBucket("products")
+	products := acme.NewTable(FldMap{"name": "str"}, NotShared, "products")
+	if !samePath(products.BktPath, []string{"tenants", "acme", "products"}) {
+		t.Fatal("NewTable BktPath wrong: ", products.BktPath)
+	}
+	skuNo := acme.DefineSeq("sku", SeqOpts{Width: 3})
+	products.SetKeyGenerator(skuNo)
+	products.CreateRecMap()
+	tx := StartDBWrite()
+	products.AddRec(products.NewKey(tx), ValMap{"name": "anvil"})
+	products.AddRec(products.NewKey(tx), ValMap{"name": "rocket"})
+	products.Save(tx)
+	CommitDBWrite(tx)
+	if skuNo.Current() != 2 || DefineSeq("sku", SeqOpts{}).Current() != 0 {
+		t.Fatal("namespace sequence not separate")
+	}
+
+	acme.RegisterSchema(FldMap{"name": "str"}, "products")
+	if GetSchema("tenants", "acme", "products")["name"] != "str" {
+		t.Fatal("namespace schema not found")
+	}
+	db.View(func(tx *bolt.Tx) error {
+		if bkt := metaBucket(tx, "schemas"); bkt != nil && bkt.Get(bs("tenants/acme/products")) != nil {
+			t.Error("namespace schema stored in top level metadata bucket")
+		}
+		return nil
+	})
+
+	acme.CreateBucket("drafts")
+	acme.RegisterSchema(FldMap{"name": "str"}, "drafts")
+	acme.DeleteBucket("drafts")
+	if GetSchema("tenants", "acme", "drafts") != nil {
+		t.Fatal("DeleteBucket left namespace schema")
+	}
+
+	globex := acme.Copy("tenants", "globex")
+	if GetSchema("tenants", "globex", "products")["name"] != "str" {
+		t.Fatal("Copy missing schema")
+	}
+	globexProducts := globex.NewTable(FldMap{"name": "str"}, NotShared, "products")
+	if globexProducts.Load() != 2 || globex.DefineSeq("sku", SeqOpts{Width: 3}).Next(nil) != "003" {
+		t.Fatal("Copy missing recs or sequence")
+	}
+	if list := globex.ListBuckets(); len(list) != 1 || list[0].Name != "products" {
+		t.Fatal("namespace ListBuckets wrong: ", list)
+	}
+
+	fileName := filepath.Join(os.TempDir(), "bo-export-test.db")
+	os.Remove(fileName)
+	defer os.Remove(fileName)
+	acme.Export(fileName)
+	exported, err := bolt.Open(fileName, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bs("tenants")).Bucket(bs("acme")).Bucket(bs("products")).Get(bs("001")) == nil {
+			t.Error("exported rec missing")
+		}
+		if tx.Bucket(bs("tenants")).Bucket(bs("globex")) != nil {
+			t.Error("export includes other tenant")
+		}
+		if rootMetaBucket(tx, []string{"tenants", "acme"}, "schemas").Get(bs("products")) == nil {
+			t.Error("export missing schema")
+		}
+		return nil
+	})
+	exported.Close()
+
+	products.SetBktPath("tenants", "globex", "products")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("read outside namespace did not panic")
+			}
+		}()
+		products.Load()
+	}()
+
+	globex.Delete()
+	if globex.Exists() || !acme.Exists() {
+		t.Fatal("Delete failed")
+	}
+	globex.Create()
+	globex.CreateBucket("products")
+	if GetSchema("tenants", "globex", "products") != nil {
+		t.Fatal("recreated namespace has deleted namespace's schema")
+	}
+	globex.Delete()
+}
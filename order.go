@@ -0,0 +1,267 @@
+package bo
+
+import (
+	"container/heap"
+	"log"
+	"sort"
+)
+
+// liveOrder is a sort order kept up to date as recs are added, changed and deleted.
+// recs and Table.OrderBy[name] contain the same keys in the same order.
+type liveOrder struct {
+	name  string
+	specs []sortSpec
+	flds  map[string]bool     // flds used by specs
+	recs  sortRecs            // in sorted order
+	byKey map[string]*sortRec // entry in recs for each key
+}
+
+// CreateLiveOrderBy creates an OrderBy entry, like CreateOrderBy, that is kept in order as recs
+// are added (AddRec), changed (Rec Set methods, Revert) and deleted (DeleteRec).
+// It is rebuilt when recs are loaded. Recs with equal sort values are in key order.
+// Ex: tbl.CreateLiveOrderBy("byAmt", "amt:d"); tbl.Loop(fn, "byAmt") is always in amt order.
+func (this *Table) CreateLiveOrderBy(orderByName string, sortBy ...string) {
+	this.StartWrite()
+	if this.liveOrders == nil {
+		this.liveOrders = make(map[string]*liveOrder)
+	}
+	delete(this.orderSpecs, orderByName) // replaces static order with same name
+	order := &liveOrder{name: orderByName, specs: this.parseSortBy(sortBy), flds: make(map[string]bool)}
+	for _, spec := range order.specs {
+		order.flds[spec.fld] = true
+	}
+	this.liveOrders[orderByName] = order
+	this.buildLiveOrder(order)
+	this.EndWrite()
+}
+
+// DropLiveOrderBy stops maintaining a live order and removes it from OrderBy.
+func (this *Table) DropLiveOrderBy(orderByName string) {
+	this.StartWrite()
+	delete(this.liveOrders, orderByName)
+	delete(this.OrderBy, orderByName)
+	this.EndWrite()
+}
+
+// buildLiveOrder sorts all recs in RecMap (except recs marked for deletion).
+func (this *Table) buildLiveOrder(order *liveOrder) {
+	order.recs = make(sortRecs, 0, len(this.RecMap))
+	order.byKey = make(map[string]*sortRec, len(this.RecMap))
+	for key, rec := range this.RecMap {
+		if rec.Vals["#delete"] == "1" {
+			continue
+		}
+		srtRec := this.newSortRec(key, *rec, order.specs)
+		order.recs = append(order.recs, srtRec)
+		order.byKey[key] = srtRec
+	}
+	sort.Sort(order.recs)
+	keys := make([]string, len(order.recs))
+	for i, srtRec := range order.recs {
+		keys[i] = srtRec.recKey
+	}
+	if this.OrderBy == nil {
+		this.OrderBy = make(map[string][]string)
+	}
+	this.OrderBy[order.name] = keys
+}
+
+// rebuildLiveOrders rebuilds all live orders, called after recs are loaded.
+func (this *Table) rebuildLiveOrders() {
+	for _, order := range this.liveOrders {
+		this.buildLiveOrder(order)
+	}
+}
+
+// remove deletes key from order.
+func (this *liveOrder) remove(tbl *Table, key string) {
+	srtRec, found := this.byKey[key]
+	if !found {
+		return
+	}
+	i := sort.Search(len(this.recs), func(i int) bool {
+		return compareWithKey(this.recs[i], srtRec) >= 0
+	})
+	keys := tbl.OrderBy[this.name]
+	copy(this.recs[i:], this.recs[i+1:])
+	this.recs = this.recs[:len(this.recs)-1]
+	copy(keys[i:], keys[i+1:])
+	tbl.OrderBy[this.name] = keys[:len(keys)-1]
+	delete(this.byKey, key)
+}
+
+// insert adds rec to order in its sorted position.
+func (this *liveOrder) insert(tbl *Table, key string, rec Rec) {
+	srtRec := tbl.newSortRec(key, rec, this.specs)
+	i := sort.Search(len(this.recs), func(i int) bool {
+		return compareWithKey(this.recs[i], srtRec) > 0
+	})
+	keys := tbl.OrderBy[this.name]
+	this.recs = append(this.recs, nil)
+	copy(this.recs[i+1:], this.recs[i:])
+	this.recs[i] = srtRec
+	keys = append(keys, "")
+	copy(keys[i+1:], keys[i:])
+	keys[i] = key
+	tbl.OrderBy[this.name] = keys
+	this.byKey[key] = srtRec
+}
+
+// liveChange calls change, which changes fld of rec with key, keeping live orders in order.
+// If fld is "", all live orders are updated. Live orders are only updated if rec is the
+// RecMap entry for key after change (ex. not a rec returned by Iterator.Rec).
+func (this *Table) liveChange(key string, rec Rec, fld string, change func()) {
+	change()
+	if len(this.liveOrders) == 0 {
+		return
+	}
+	if cur := this.RecMap[key]; cur == nil || rec.chg == nil || cur.chg != rec.chg {
+		return
+	}
+	for _, order := range this.liveOrders {
+		if fld != "" && !order.flds[fld] {
+			continue
+		}
+		order.remove(this, key) // uses sort values saved when key was inserted
+		if rec.Vals["#delete"] != "1" {
+			order.insert(this, key, rec)
+		}
+	}
+}
+
+// --- access to part of an order -------------------------------------
+
+// topRecs is a heap with the rec that sorts last on top, used by TopN.
+type topRecs struct {
+	sortRecs
+}
+
+func (this *topRecs) Less(a, b int) bool {
+	return compareWithKey(this.sortRecs[a], this.sortRecs[b]) > 0
+}
+func (this *topRecs) Push(x interface{}) {
+	this.sortRecs = append(this.sortRecs, x.(*sortRec))
+}
+func (this *topRecs) Pop() interface{} {
+	last := this.sortRecs[len(this.sortRecs)-1]
+	this.sortRecs = this.sortRecs[:len(this.sortRecs)-1]
+	return last
+}
+
+// TopN returns keys of the first n recs in order based on sortBy (same as CreateOrderBy).
+// Uses a heap of n recs, so RecMap is not fully sorted. Deleted recs are skipped.
+// Ex: tbl.TopN(10, "amt:d") returns keys of 10 largest amts, largest 1st.
+func (this *Table) TopN(n int, sortBy ...string) []string {
+	specs := this.parseSortBy(sortBy)
+	if n < 1 {
+		return []string{}
+	}
+	top := &topRecs{make(sortRecs, 0, n)}
+	for key, rec := range this.RecMap {
+		if rec.Vals["#delete"] == "1" {
+			continue
+		}
+		srtRec := this.newSortRec(key, *rec, specs)
+		if top.Len() < n {
+			heap.Push(top, srtRec)
+		} else if compareWithKey(srtRec, top.sortRecs[0]) < 0 {
+			top.sortRecs[0] = srtRec
+			heap.Fix(top, 0)
+		}
+	}
+	keys := make([]string, top.Len())
+	for i := len(keys) - 1; i >= 0; i-- {
+		keys[i] = heap.Pop(top).(*sortRec).recKey
+	}
+	return keys
+}
+
+// LoopLimit is the same as Loop, except fn is called for at most limit recs,
+// starting at position offset in OrderBy[orderBy]. A negative offset is treated as 0,
+// a negative limit means no limit.
+func (this *Table) LoopLimit(fn func(key string, rec *Rec), orderBy string, offset, limit int) {
+	keys := this.orderKeys(orderBy)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(keys) {
+		offset = len(keys)
+	}
+	end := offset + limit
+	if end > len(keys) || limit < 0 {
+		end = len(keys)
+	}
+	this.loopKeys(fn, keys[offset:end])
+}
+
+// SeekOrder returns position in OrderBy[orderBy] of the 1st rec with sort values at or after vals.
+// vals are string values of the order's 1st sortBy flds (fewer vals than sortBy flds is ok).
+// For "byKey", vals[0] is a key. Returns len(OrderBy[orderBy]) if all recs sort before vals.
+// The order must be a "byKey" order or created by CreateOrderBy or CreateLiveOrderBy and be current.
+func (this *Table) SeekOrder(orderBy string, vals ...string) int {
+	if len(vals) == 0 {
+		log.Panic("SeekOrder requires at least 1 val, orderBy: ", orderBy)
+	}
+	keys := this.orderKeys(orderBy)
+	if orderBy == "byKey" {
+		return sort.SearchStrings(keys, vals[0])
+	}
+	probe, specs := this.orderProbe(orderBy, vals)
+	return sort.Search(len(keys), func(i int) bool {
+		return compareSortRecs(probe, this.newSortRec(keys[i], *this.RecMap[keys[i]], specs)) <= 0
+	})
+}
+
+// LoopRange calls fn for recs in OrderBy[orderBy] whose 1st sortBy fld value is from "from" to "to".
+// Empty from starts at the 1st rec, empty to continues through the last rec.
+// For "byKey", from & to are keys. See SeekOrder for requirements.
+func (this *Table) LoopRange(fn func(key string, rec *Rec), orderBy, from, to string) {
+	keys := this.orderKeys(orderBy)
+	start, end := 0, len(keys)
+	if from != "" {
+		start = this.SeekOrder(orderBy, from)
+	}
+	if to != "" && orderBy == "byKey" {
+		end = sort.Search(len(keys), func(i int) bool {
+			return keys[i] > to
+		})
+	} else if to != "" {
+		probe, specs := this.orderProbe(orderBy, []string{to})
+		end = sort.Search(len(keys), func(i int) bool {
+			return compareSortRecs(probe, this.newSortRec(keys[i], *this.RecMap[keys[i]], specs)) < 0
+		})
+	}
+	if start < end {
+		this.loopKeys(fn, keys[start:end])
+	}
+}
+
+// orderKeys returns OrderBy[orderBy], aborting if not found.
+func (this *Table) orderKeys(orderBy string) []string {
+	keys, found := this.OrderBy[orderBy]
+	if !found {
+		log.Panic("orderBy Not Found: ", orderBy)
+	}
+	return keys
+}
+
+// orderProbe returns a sortRec containing vals, for searching an order, plus the order's specs.
+func (this *Table) orderProbe(orderBy string, vals []string) (*sortRec, []sortSpec) {
+	var specs []sortSpec
+	if order, found := this.liveOrders[orderBy]; found {
+		specs = order.specs
+	} else if sortBy, found := this.orderSpecs[orderBy]; found {
+		specs = this.parseSortBy(sortBy)
+	} else {
+		log.Panic("orderBy not created by CreateOrderBy or CreateLiveOrderBy: ", orderBy)
+	}
+	if len(vals) > len(specs) {
+		log.Panic("too many vals for orderBy: ", orderBy, vals)
+	}
+	probeVals := make(ValMap)
+	for i, val := range vals {
+		probeVals[specs[i].fld] = val
+	}
+	probe := this.newSortRec("", Rec{Tbl: this, Vals: probeVals}, specs[:len(vals)])
+	return probe, specs
+}
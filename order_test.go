@@ -0,0 +1,126 @@
+// These tests check live orders are kept in order as recs change.
+
+package bo
+
+import (
+	"testing"
+)
+
+var bidFlds = FldMap{
+	"bidder": "str",
+	"amt":    "float",
+}
+
+func checkOrder(t *testing.T, tbl *Table, orderByName string, want ...string) {
+	keys := tbl.OrderBy[orderByName]
+	if len(keys) != len(want) {
+		t.Fatal(orderByName, " wrong: ", keys)
+	}
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Fatal(orderByName, " wrong: ", keys)
+		}
+	}
+}
+
+func TestLiveOrderBy(t *testing.T) {
+	CreateBucket("bids")
+	tbl := NewTable(bidFlds, NotShared, "bids")
+	tbl.CreateRecMap()
+	tbl.CreateLiveOrderBy("byAmt", "amt:d")
+	tbl.AddRec("b1", ValMap{"bidder": "ann", "amt": "10"})
+	tbl.AddRec("b2", ValMap{"bidder": "bob", "amt": "30"})
+	tbl.AddRec("b3", ValMap{"bidder": "cal", "amt": "20"})
+	tbl.AddRec("b4", ValMap{"bidder": "dee", "amt": "20"})
+	checkOrder(t, tbl, "byAmt", "b2", "b3", "b4", "b1")
+
+	tbl.GetRec("b1").SetFloat("amt", 40)
+	checkOrder(t, tbl, "byAmt", "b1", "b2", "b3", "b4")
+	tbl.GetRec("b1").Set("bidder", "amy") // fld not in order
+	tbl.GetRec("b1").Revert("amt")
+	checkOrder(t, tbl, "byAmt", "b2", "b3", "b4", "b1")
+	tbl.DeleteRec("b3")
+	checkOrder(t, tbl, "byAmt", "b2", "b4", "b1")
+
+	var count int
+	tbl.Loop(func(key string, rec *Rec) {
+		count++
+	}, "byAmt")
+	if count != 3 {
+		t.Fatal("Loop with live order count wrong: ", count)
+	}
+
+	tx := StartDBWrite()
+	tbl.Save(tx)
+	CommitDBWrite(tx)
+	tbl.Load()
+	checkOrder(t, tbl, "byAmt", "b2", "b4", "b1")
+
+	iter := tbl.Iterator(ScanOpts{Prefix: "b2"}) // rec not in RecMap does not change live order
+	for iter.Next() {
+		iter.Rec().SetFloat("amt", 1)
+	}
+	checkOrder(t, tbl, "byAmt", "b2", "b4", "b1")
+
+	tbl.DropLiveOrderBy("byAmt")
+	tbl.GetRec("b1").SetFloat("amt", 50)
+	if _, found := tbl.OrderBy["byAmt"]; found {
+		t.Fatal("dropped live order still in OrderBy")
+	}
+}
+
+func TestOrderAccess(t *testing.T) {
+	tbl := NewTable(bidFlds, NotShared)
+	tbl.CreateRecMap()
+	amts := []string{"5", "80", "15", "40", "15", "60", "25"}
+	for i, amt := range amts {
+		tbl.AddRec("b"+IntToStr(int64(i)), ValMap{"bidder": "x", "amt": amt})
+	}
+	tbl.DeleteRec("b1") // amt 80
+	top := tbl.TopN(3, "amt:d")
+	if len(top) != 3 || top[0] != "b5" || top[1] != "b3" || top[2] != "b6" {
+		t.Fatal("TopN wrong: ", top)
+	}
+
+	tbl.CreateOrderBy("byAmt", "amt")
+	checkOrder(t, tbl, "byAmt", "b0", "b2", "b4", "b6", "b3", "b5", "b1")
+	keys := make([]string, 0)
+	collect := func(key string, rec *Rec) {
+		keys = append(keys, key)
+	}
+	tbl.LoopLimit(collect, "byAmt", 2, 3)
+	if len(keys) != 3 || keys[0] != "b4" || keys[2] != "b3" {
+		t.Fatal("LoopLimit wrong: ", keys)
+	}
+	keys = keys[:0]
+	tbl.LoopLimit(collect, "byAmt", -5, 2)
+	if len(keys) != 2 || keys[0] != "b0" {
+		t.Fatal("LoopLimit negative offset wrong: ", keys)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("SeekOrder without vals did not panic")
+			}
+		}()
+		tbl.SeekOrder("byKey")
+	}()
+	if i := tbl.SeekOrder("byAmt", "15"); i != 1 {
+		t.Fatal("SeekOrder wrong: ", i)
+	}
+	if i := tbl.SeekOrder("byAmt", "100"); i != len(amts) {
+		t.Fatal("SeekOrder past end wrong: ", i)
+	}
+	keys = keys[:0]
+	tbl.LoopRange(collect, "byAmt", "15", "40")
+	if len(keys) != 4 || keys[0] != "b2" || keys[3] != "b3" {
+		t.Fatal("LoopRange wrong: ", keys)
+	}
+
+	tbl.CreateLiveOrderBy("byAmtDesc", "amt:d")
+	keys = keys[:0]
+	tbl.LoopRange(collect, "byAmtDesc", "60", "25")
+	if len(keys) != 3 || keys[0] != "b5" || keys[2] != "b6" {
+		t.Fatal("LoopRange descending wrong: ", keys)
+	}
+}
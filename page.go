@@ -0,0 +1,134 @@
+package bo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// PageToken identifies where a page of recs ends, so the next (or previous) page can be loaded.
+// Use String to pass it to a client (ex. in a url) and ParsePageToken to convert it back.
+type PageToken struct {
+	BktPath []string `json:"b"`
+	Key     string   `json:"k"` // LoadPage startAfter value
+	Reverse bool     `json:"r"`
+}
+
+// pageInfo holds the bounds and results of paged loading.
+type pageInfo struct {
+	bounds ScanOpts // Start, End, Prefix limit recs that can be loaded by LoadPage
+	more   bool     // last LoadPage stopped before end of bounds
+}
+
+// SetPagePrefix limits recs loaded by LoadPage to keys beginning with prefix (like LoadPrefix).
+func (this *Table) SetPagePrefix(prefix string) {
+	this.page.bounds = ScanOpts{Prefix: prefix}
+}
+
+// SetPageRange limits recs loaded by LoadPage to keys from start to end (like LoadRange).
+// Empty start or end means no limit on that side.
+func (this *Table) SetPageRange(start, end string) {
+	this.page.bounds = ScanOpts{Start: start, End: end}
+}
+
+// LoadPage loads up to limit recs with keys following startAfter.
+// If reverse is true, loads recs with keys preceding startAfter.
+// An empty startAfter begins at the start (or end if reverse) of the page bounds.
+// RecMap is recreated. OrderBy["byKey"] contains the page's keys in ascending order.
+// Returns count of recs loaded. Limit must be greater than 0.
+func (this *Table) LoadPage(startAfter string, limit int, reverse bool) int {
+	if limit < 1 {
+		log.Panic("LoadPage limit must be greater than 0: ", limit)
+	}
+	this.StartWrite()
+	this.RecMap = make(map[string]*Rec)
+	this.OrderBy = make(map[string][]string)
+	this.sels = nil
+	opts := this.page.bounds
+	opts.Reverse = reverse
+	if startAfter != "" {
+		if reverse && (opts.End == "" || startAfter < opts.End) {
+			opts.End = startAfter
+		}
+		if !reverse && startAfter > opts.Start {
+			opts.Start = startAfter
+		}
+	}
+	keys := make([]string, 0, limit)
+	this.page.more = false
+	iter := this.Iterator(opts)
+	for iter.Next() {
+		key := iter.Key()
+		if key == startAfter {
+			continue
+		}
+		if len(keys) == limit {
+			this.page.more = true
+			break
+		}
+		this.RecMap[key] = iter.Rec()
+		keys = append(keys, key)
+	}
+	iter.Close()
+	if reverse {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+	this.OrderBy["byKey"] = keys
+	this.rebuildLiveOrders()
+	this.EndWrite()
+	return len(this.RecMap)
+}
+
+// MorePages returns true if the last LoadPage stopped before reaching the end of the page bounds
+// (or the start if reverse was true).
+func (this *Table) MorePages() bool {
+	return this.page.more
+}
+
+// NextPage returns token for loading the page following the currently loaded page.
+func (this *Table) NextPage() PageToken {
+	token := PageToken{BktPath: this.BktPath}
+	if keys := this.OrderBy["byKey"]; len(keys) > 0 {
+		token.Key = keys[len(keys)-1]
+	}
+	return token
+}
+
+// PrevPage returns token for loading the page preceding the currently loaded page.
+func (this *Table) PrevPage() PageToken {
+	token := PageToken{BktPath: this.BktPath, Reverse: true}
+	if keys := this.OrderBy["byKey"]; len(keys) > 0 {
+		token.Key = keys[0]
+	}
+	return token
+}
+
+// LoadPageToken loads the page identified by token, see LoadPage.
+// Token's BktPath must match the Table's BktPath.
+func (this *Table) LoadPageToken(token PageToken, limit int) int {
+	if strings.Join(token.BktPath, "/") != strings.Join(this.BktPath, "/") {
+		log.Panic("LoadPageToken bktPath mismatch, token: ", token.BktPath, ", table: ", this.BktPath)
+	}
+	return this.LoadPage(token.Key, limit, token.Reverse)
+}
+
+// String returns token encoded as a url safe string.
+func (this PageToken) String() string {
+	jsonBytes, _ := json.Marshal(this)
+	return base64.RawURLEncoding.EncodeToString(jsonBytes)
+}
+
+// ParsePageToken converts a string created by PageToken.String back to a PageToken.
+// Since tokens usually come from clients, an error is returned instead of panicking.
+func ParsePageToken(token string) (PageToken, error) {
+	var pageToken PageToken
+	jsonBytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return pageToken, err
+	}
+	err = json.Unmarshal(jsonBytes, &pageToken)
+	return pageToken, err
+}
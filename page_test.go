@@ -0,0 +1,60 @@
+// These tests load a "pages" bucket 1 page at a time.
+
+package bo
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLoadPage(t *testing.T) {
+	CreateBucket("pages")
+	pageFlds := FldMap{"grp": "str"}
+	tbl := NewTable(pageFlds, NotShared, "pages")
+	tbl.CreateRecMap()
+	for _, grp := range []string{"a", "b"} {
+		for i := 1; i <= 12; i++ {
+			tbl.AddRec(fmt.Sprintf("%s%02d", grp, i), ValMap{"grp": grp})
+		}
+	}
+	tx := StartDBWrite()
+	tbl.Save(tx)
+	CommitDBWrite(tx)
+
+	tbl = NewTable(pageFlds, NotShared, "pages")
+	tbl.SetPagePrefix("a")
+	if count := tbl.LoadPage("", 5, false); count != 5 || tbl.OrderBy["byKey"][0] != "a01" || !tbl.MorePages() {
+		t.Fatal("LoadPage 1st page wrong: ", tbl.OrderBy["byKey"])
+	}
+	tokenStr := tbl.NextPage().String()
+	token, err := ParsePageToken(tokenStr)
+	if err != nil {
+		t.Fatal("ParsePageToken failed: ", err)
+	}
+	tbl.LoadPageToken(token, 5)
+	if keys := tbl.OrderBy["byKey"]; len(keys) != 5 || keys[0] != "a06" || keys[4] != "a10" {
+		t.Fatal("LoadPage 2nd page wrong: ", keys)
+	}
+	tbl.LoadPageToken(tbl.NextPage(), 5)
+	if keys := tbl.OrderBy["byKey"]; len(keys) != 2 || keys[1] != "a12" || tbl.MorePages() {
+		t.Fatal("LoadPage last page wrong: ", keys)
+	}
+	tbl.LoadPageToken(tbl.PrevPage(), 5)
+	if keys := tbl.OrderBy["byKey"]; len(keys) != 5 || keys[0] != "a06" || keys[4] != "a10" {
+		t.Fatal("LoadPage previous page wrong: ", keys)
+	}
+
+	tbl.SetPageRange("a11", "b02")
+	if tbl.LoadPage("", 10, true); len(tbl.OrderBy["byKey"]) != 4 || tbl.OrderBy["byKey"][0] != "a11" {
+		t.Fatal("LoadPage reverse range wrong: ", tbl.OrderBy["byKey"])
+	}
+	if _, err := ParsePageToken("not a token"); err == nil {
+		t.Fatal("ParsePageToken accepted bad token")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("LoadPage negative limit did not panic")
+		}
+	}()
+	tbl.LoadPage("", -1, false)
+}
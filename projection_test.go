@@ -0,0 +1,57 @@
+// These tests load only some flds of recs containing a large bytes fld.
+
+package bo
+
+import (
+	"bytes"
+	"testing"
+)
+
+var receiptFlds = FldMap{
+	"id":    "str",
+	"date":  "date",
+	"amt":   "float",
+	"image": "bytes",
+}
+
+func TestLoadFlds(t *testing.T) {
+	CreateBucket("receipts")
+	image := bytes.Repeat([]byte("receipt image "), 100)
+	tbl := NewTable(receiptFlds, NotShared, "receipts")
+	tbl.CreateRecMap()
+	for _, key := range []string{"r1", "r2"} {
+		rec := tbl.AddRec(key, ValMap{"id": key, "date": "2016-08-22", "amt": "1.5"})
+		rec.SetBytes("image", image)
+	}
+	tx := StartDBWrite()
+	tbl.Save(tx)
+	CommitDBWrite(tx)
+
+	list := NewTable(receiptFlds, NotShared, "receipts")
+	list.SetLoadFlds("id", "amt")
+	list.Load()
+	rec := list.GetRec("r1")
+	if len(rec.Vals) != 2 || rec.Get("id") != "r1" || rec.Get("date", "none") != "none" {
+		t.Fatal("SetLoadFlds loaded wrong flds: ", rec.Vals)
+	}
+	rec.SetFloat("amt", 2.5)
+	tx = StartDBWrite()
+	list.Save(tx)
+	CommitDBWrite(tx)
+	if len(rec.Vals) != 2 {
+		t.Fatal("partial rec got unloaded flds after Save: ", rec.Vals)
+	}
+	list.Scan(ScanOpts{}, func(key string, rec *Rec) bool {
+		if _, found := rec.Vals["image"]; found {
+			t.Fatal("Scan decoded fld not in load flds")
+		}
+		return true
+	})
+
+	tbl = NewTable(receiptFlds, NotShared, "receipts")
+	tbl.Load1("r1")
+	rec = tbl.GetRec("r1")
+	if rec.GetFloat("amt") != 2.5 || rec.Get("date") != "2016-08-22" || !bytes.Equal(rec.GetBytes("image"), image) {
+		t.Fatal("Save of partial rec lost flds: ", rec.Vals["amt"], rec.Vals["date"])
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/base64"
 	"log"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -17,6 +19,20 @@ type ValMap map[string]string
 type Rec struct {
 	Tbl  *Table
 	Vals ValMap
+	chg  *recChanges // field level change tracking, nil if Rec not created by Table
+}
+
+// recChanges holds the original value of each fld changed since rec was loaded or saved.
+// A pointer is used so Rec methods with value receivers can update it.
+type recChanges struct {
+	key     string
+	added   bool // rec was added with AddRec, all flds are considered changed
+	partial bool // only some flds were loaded (see Table.SetLoadFlds), Save merges with db rec
+	orig    map[string]origVal
+}
+type origVal struct {
+	val   string
+	found bool // false if fld had no value
 }
 
 // --- Rec Get methods --------------------------------------------
@@ -150,48 +166,42 @@ func (rec Rec) Set(fld, val string) {
 	if ok := validFld(rec.Tbl.Flds, fld); !ok {
 		log.Panic("invalid fld: ", fld)
 	}
-	rec.Vals[fld] = val
-	rec.Vals["#c"] = "1"
+	rec.setVal(fld, val)
 }
 
 func (rec Rec) SetBytes(fld string, val []byte) {
 	if ok := validFld(rec.Tbl.Flds, fld); !ok {
 		log.Panic("invalid fld: ", fld)
 	}
-	rec.Vals[fld] = base64.StdEncoding.EncodeToString(val)
-	rec.Vals["#c"] = "1"
+	rec.setVal(fld, base64.StdEncoding.EncodeToString(val))
 }
 
 func (rec Rec) SetInt(fld string, val int64) {
 	if ok := validFld(rec.Tbl.Flds, fld); !ok {
 		log.Panic("invalid fld: ", fld)
 	}
-	rec.Vals[fld] = IntToStr(val)
-	rec.Vals["#c"] = "1"
+	rec.setVal(fld, IntToStr(val))
 }
 
 func (rec Rec) SetFloat(fld string, val float64) {
 	if ok := validFld(rec.Tbl.Flds, fld); !ok {
 		log.Panic("invalid fld: ", fld)
 	}
-	rec.Vals[fld] = FloatToStr(val)
-	rec.Vals["#c"] = "1"
+	rec.setVal(fld, FloatToStr(val))
 }
 
 func (rec Rec) SetDate(fld string, date time.Time) {
 	if ok := validFld(rec.Tbl.Flds, fld); !ok {
 		log.Panic("invalid fld: ", fld)
 	}
-	rec.Vals[fld] = date.Format(DateFormat)
-	rec.Vals["#c"] = "1"
+	rec.setVal(fld, date.Format(DateFormat))
 }
 
 func (rec Rec) SetDateTime(fld string, dateTime time.Time) {
 	if ok := validFld(rec.Tbl.Flds, fld); !ok {
 		log.Panic("invalid fld: ", fld)
 	}
-	rec.Vals[fld] = dateTime.Format(DateTimeFormat)
-	rec.Vals["#c"] = "1"
+	rec.setVal(fld, dateTime.Format(DateTimeFormat))
 }
 
 func (rec Rec) SetBool(fld string, val bool) {
@@ -199,11 +209,159 @@ func (rec Rec) SetBool(fld string, val bool) {
 		log.Panic("invalid fld: ", fld)
 	}
 	if val {
-		rec.Vals[fld] = "true"
+		rec.setVal(fld, "true")
+	} else {
+		rec.setVal(fld, "false")
+	}
+}
+
+// setVal stores val for fld, saving fld's original value and turning on change flag.
+// If fld is used by a live order (see Table.CreateLiveOrderBy), rec is moved to its new position.
+func (rec Rec) setVal(fld, val string) {
+	if rec.chg == nil {
+		rec.Vals[fld] = val
+		rec.Vals["#c"] = "1"
+		return
+	}
+	if !strings.HasPrefix(fld, "#") {
+		if _, found := rec.chg.orig[fld]; !found {
+			old, oldFound := rec.Vals[fld]
+			rec.chg.orig[fld] = origVal{val: old, found: oldFound}
+		}
+	}
+	rec.Tbl.liveChange(rec.chg.key, rec, fld, func() {
+		rec.Vals[fld] = val
+		rec.Vals["#c"] = "1"
+	})
+}
+
+// --- Rec change tracking methods -----------------------------
+
+// ChangedFields returns names of flds whose values differ from when rec was loaded or last saved.
+// For recs added with AddRec, all flds with a value are returned.
+func (rec Rec) ChangedFields() []string {
+	changed := make([]string, 0)
+	if rec.chg == nil {
+		return changed
+	}
+	if rec.chg.added {
+		for fld := range rec.Vals {
+			if !strings.HasPrefix(fld, "#") {
+				changed = append(changed, fld)
+			}
+		}
 	} else {
-		rec.Vals[fld] = "false"
+		for fld, orig := range rec.chg.orig {
+			val, found := rec.Vals[fld]
+			if val != orig.val || found != orig.found {
+				changed = append(changed, fld)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Original returns value of fld when rec was loaded or last saved.
+// If fld has not been changed, the current value is returned.
+func (rec Rec) Original(fld string) string {
+	if ok := validFld(rec.Tbl.Flds, fld); !ok {
+		log.Panic("invalid fld: ", fld)
+	}
+	if rec.chg != nil {
+		if orig, found := rec.chg.orig[fld]; found {
+			return orig.val
+		}
+	}
+	return rec.Vals[fld]
+}
+
+// Revert restores fld to its original value.
+// If no changed flds remain, the change flag is turned off (except for added recs).
+func (rec Rec) Revert(fld string) {
+	if ok := validFld(rec.Tbl.Flds, fld); !ok {
+		log.Panic("invalid fld: ", fld)
+	}
+	if rec.chg == nil {
+		return
+	}
+	orig, found := rec.chg.orig[fld]
+	if !found {
+		return
+	}
+	rec.Tbl.liveChange(rec.chg.key, rec, fld, func() {
+		if orig.found {
+			rec.Vals[fld] = orig.val
+		} else {
+			delete(rec.Vals, fld)
+		}
+	})
+	delete(rec.chg.orig, fld)
+	if !rec.chg.added && len(rec.ChangedFields()) == 0 {
+		delete(rec.Vals, "#c")
+	}
+}
+
+// originalVals returns copy of rec's values as they were when loaded or last saved.
+// Special flds (ex. "#c") are not included.
+func (rec Rec) originalVals() ValMap {
+	vals := make(ValMap, len(rec.Vals))
+	for fld, val := range rec.Vals {
+		if !strings.HasPrefix(fld, "#") {
+			vals[fld] = val
+		}
+	}
+	if rec.chg == nil {
+		return vals
+	}
+	for fld, orig := range rec.chg.orig {
+		if orig.found {
+			vals[fld] = orig.val
+		} else {
+			delete(vals, fld)
+		}
+	}
+	return vals
+}
+
+// replaceVals replaces all of rec's values with vals, turning off change flag and tracking.
+// The Vals map is updated in place.
+func (rec Rec) replaceVals(vals ValMap) {
+	for fld := range rec.Vals {
+		delete(rec.Vals, fld)
+	}
+	for fld, val := range vals {
+		rec.Vals[fld] = val
+	}
+	rec.clearChanges()
+}
+
+// valsEqual returns true if a and b contain the same flds and values, special flds are ignored.
+func valsEqual(a, b ValMap) bool {
+	var countA, countB int
+	for fld, valA := range a {
+		if strings.HasPrefix(fld, "#") {
+			continue
+		}
+		countA++
+		if valB, found := b[fld]; !found || valA != valB {
+			return false
+		}
+	}
+	for fld := range b {
+		if !strings.HasPrefix(fld, "#") {
+			countB++
+		}
+	}
+	return countA == countB
+}
+
+// clearChanges removes change tracking info, called after rec is saved.
+func (rec Rec) clearChanges() {
+	if rec.chg != nil {
+		rec.chg.added = false
+		rec.chg.orig = make(map[string]origVal)
 	}
-	rec.Vals["#c"] = "1"
 }
 
 var quote byte = 34 // ascii codes
@@ -235,10 +393,16 @@ func (this ValMap) toJson() []byte {
 
 // fromJson adds entries to ValMap from jsonBytes
 func (this ValMap) fromJson(jsonBytes []byte) {
+	this.fromJsonFlds(jsonBytes, nil)
+}
+
+// fromJsonFlds adds entries to ValMap from jsonBytes, only for keys in flds.
+// If flds is nil, all entries are added. Values of other keys are skipped without being copied.
+func (this ValMap) fromJsonFlds(jsonBytes []byte, flds map[string]bool) {
 	var offset int     // current position in buffer
 	var qx int         // index of next quote
 	var begx, endx int // beginning,end indexes of key or val to be extracted
-	var key, val string
+	var key []byte
 	for {
 		if offset > len(jsonBytes) {
 			log.Panic("ValMap.fromJson, bad json\n", string(jsonBytes))
@@ -253,7 +417,7 @@ func (this ValMap) fromJson(jsonBytes []byte) {
 
 		qx = bytes.IndexByte(jsonBytes[offset:], quote) // end quote for key
 		endx = offset + qx
-		key = string(jsonBytes[begx:endx])
+		key = jsonBytes[begx:endx]
 		offset += qx + 1
 
 		// --- get value ------------------------------------
@@ -263,9 +427,9 @@ func (this ValMap) fromJson(jsonBytes []byte) {
 
 		qx = bytes.IndexByte(jsonBytes[offset:], quote) // end quote for val
 		endx = offset + qx
-		val = string(jsonBytes[begx:endx])
-
-		this[key] = val
+		if flds == nil || flds[string(key)] {
+			this[string(key)] = string(jsonBytes[begx:endx])
+		}
 		offset += qx + 1
 	}
 }
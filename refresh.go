@@ -0,0 +1,108 @@
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"sort"
+)
+
+// RefreshResult lists keys of recs affected by Refresh, each slice is in key order.
+type RefreshResult struct {
+	Updated   []string // unchanged recs replaced with newer db version
+	Added     []string // recs matching the load selection, added to db since loaded
+	Deleted   []string // recs deleted from db, removed from RecMap (also if marked for deletion in RecMap)
+	Conflicts []string // recs changed in RecMap and in db, RecMap version is kept
+}
+
+// Refresh re-reads recs in RecMap plus recs matching the selections used by the Load
+// (or Merge) methods that filled RecMap. Recs without unsaved changes are updated, recs added
+// to the db are added to RecMap, recs deleted from the db are removed from RecMap.
+// Recs changed in RecMap and in the db are reported as conflicts and not changed.
+// A rec marked for deletion in RecMap that is already deleted from the db is removed from RecMap.
+// OrderBy["byKey"] and OrderBy entries created by CreateOrderBy are rebuilt.
+func (this *Table) Refresh() RefreshResult {
+	return this.refresh(this.sels)
+}
+
+// RefreshKeys is the same as Refresh, except only keys currently in RecMap are re-read.
+func (this *Table) RefreshKeys() RefreshResult {
+	return this.refresh(nil)
+}
+
+func (this *Table) refresh(sels []selection) RefreshResult {
+	var result RefreshResult
+	this.StartWrite()
+	if this.RecMap == nil {
+		this.RecMap = make(map[string]*Rec)
+		this.OrderBy = make(map[string][]string)
+	}
+	dbVals := make(map[string]ValMap) // key is rec key
+	db.View(func(tx *bolt.Tx) error {
+		bkt := this.openBucket(tx)
+		for key := range this.RecMap {
+			if v := bkt.Get(bs(key)); v != nil {
+				dbVals[key] = this.loadedRec(key, v).Vals
+			}
+		}
+		for _, sel := range sels {
+			sel.read(bkt, func(k, v []byte) {
+				key := string(k)
+				if _, found := dbVals[key]; !found {
+					dbVals[key] = this.loadedRec(key, v).Vals
+				}
+			})
+		}
+		return nil
+	})
+	for key, rec := range this.RecMap {
+		vals, inDB := dbVals[key]
+		changed := rec.Vals["#c"] == "1" || rec.Vals["#delete"] == "1"
+		switch {
+		case rec.chg != nil && rec.chg.added:
+			if inDB {
+				result.Conflicts = append(result.Conflicts, key)
+			}
+		case !inDB:
+			if changed && rec.Vals["#delete"] != "1" { // deleted in RecMap and db is not a conflict
+				result.Conflicts = append(result.Conflicts, key)
+			} else {
+				delete(this.RecMap, key)
+				result.Deleted = append(result.Deleted, key)
+			}
+		case !changed:
+			if !valsEqual(rec.Vals, vals) {
+				rec.replaceVals(vals)
+				result.Updated = append(result.Updated, key)
+			}
+		default:
+			if !valsEqual(rec.originalVals(), vals) {
+				result.Conflicts = append(result.Conflicts, key)
+			}
+		}
+	}
+	for key, vals := range dbVals {
+		if _, found := this.RecMap[key]; !found {
+			this.RecMap[key] = this.newRec(key, vals)
+			this.RecMap[key].chg.partial = this.loadFlds != nil
+			result.Added = append(result.Added, key)
+		}
+	}
+	if _, found := this.OrderBy["byKey"]; found {
+		keys := make([]string, 0, len(this.RecMap))
+		for key := range this.RecMap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		this.OrderBy["byKey"] = keys
+	}
+	this.rebuildLiveOrders()
+	this.EndWrite()
+	for orderByName, sortBy := range this.orderSpecs {
+		if _, found := this.OrderBy[orderByName]; found {
+			this.CreateOrderBy(orderByName, sortBy...)
+		}
+	}
+	for _, keys := range [][]string{result.Updated, result.Added, result.Deleted, result.Conflicts} {
+		sort.Strings(keys)
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+// These tests refresh a table after another "process" changes the db.
+
+package bo
+
+import (
+	"testing"
+)
+
+var stockFlds = FldMap{
+	"item": "str",
+	"qty":  "int",
+}
+
+func TestRefresh(t *testing.T) {
+	CreateBucket("stock")
+	other := NewTable(stockFlds, NotShared, "stock") // simulates another process
+	other.CreateRecMap()
+	for _, key := range []string{"a1", "a2", "a3", "a4", "a6", "b1"} {
+		other.AddRec(key, ValMap{"item": key, "qty": "5"})
+	}
+	tx := StartDBWrite()
+	other.Save(tx)
+	CommitDBWrite(tx)
+
+	tbl := NewTable(stockFlds, NotShared, "stock")
+	tbl.LoadPrefix("a")
+	tbl.CreateOrderBy("byQty", "qty:d")
+	tbl.GetRec("a2").SetInt("qty", 20) // changed locally only
+	tbl.GetRec("a3").SetInt("qty", 30) // changed locally and by other
+	tbl.DeleteRec("a6")                // deleted locally and by other
+
+	other.Load()
+	other.GetRec("a1").SetInt("qty", 10)
+	other.GetRec("a3").SetInt("qty", 31)
+	other.DeleteRec("a4")
+	other.DeleteRec("a6")
+	other.AddRec("a5", ValMap{"item": "a5", "qty": "50"})
+	other.AddRec("b2", ValMap{"item": "b2", "qty": "1"})
+	tx = StartDBWrite()
+	other.Save(tx)
+	CommitDBWrite(tx)
+
+	result := tbl.Refresh()
+	check := func(name string, keys []string, want ...string) {
+		if len(keys) != len(want) {
+			t.Fatal("Refresh ", name, " wrong: ", keys)
+		}
+		for i := range want {
+			if keys[i] != want[i] {
+				t.Fatal("Refresh ", name, " wrong: ", keys)
+			}
+		}
+	}
+	check("Updated", result.Updated, "a1")
+	check("Added", result.Added, "a5")
+	check("Deleted", result.Deleted, "a4", "a6")
+	check("Conflicts", result.Conflicts, "a3")
+	check("byKey", tbl.OrderBy["byKey"], "a1", "a2", "a3", "a5")
+	check("byQty", tbl.OrderBy["byQty"], "a5", "a3", "a2", "a1")
+	if tbl.GetRec("a1").GetInt("qty") != 10 || tbl.GetRec("a3").GetInt("qty") != 30 {
+		t.Fatal("Refresh rec values wrong")
+	}
+	if tbl.GetRec("a2").GetInt("qty") != 20 || tbl.GetRec("a2").Vals["#c"] != "1" {
+		t.Fatal("Refresh lost local change")
+	}
+
+	result = tbl.RefreshKeys()
+	if len(result.Updated)+len(result.Added)+len(result.Deleted) != 0 {
+		t.Fatal("RefreshKeys changed table: ", result)
+	}
+}
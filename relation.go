@@ -0,0 +1,268 @@
+package bo
+
+import (
+	"fmt"
+	"github.com/boltdb/bolt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// OnDelete actions, see Relation.
+const (
+	Restrict = "restrict" // deleting a referenced rec fails
+	Cascade  = "cascade"  // referencing recs are also deleted
+	SetEmpty = "setEmpty" // referencing recs fld is set to ""
+)
+
+// Relation declares that Fld of recs in BktPath contains the key of a rec in TargetPath.
+// Table.Save (and TrySave, SaveBatched) checks relations inside its transaction, before writing:
+// a rec cannot be saved if its Fld value is not empty and is not a key in TargetPath,
+// and deleting a rec in TargetPath applies OnDelete to recs referencing it.
+// Recs changed by Cascade or SetEmpty are not changed in any Table's RecMap.
+type Relation struct {
+	BktPath    []string
+	Fld        string
+	TargetPath []string
+	OnDelete   string // Restrict (default), Cascade, SetEmpty
+}
+
+var relations []Relation
+var relationsLock sync.RWMutex
+
+// AddRelation declares a relationship between 2 buckets, see Relation.
+func AddRelation(rel Relation) {
+	if rel.OnDelete == "" {
+		rel.OnDelete = Restrict
+	}
+	if rel.OnDelete != Restrict && rel.OnDelete != Cascade && rel.OnDelete != SetEmpty {
+		log.Panic("AddRelation invalid OnDelete: ", rel.OnDelete)
+	}
+	if len(rel.BktPath) == 0 || len(rel.TargetPath) == 0 || rel.Fld == "" {
+		log.Panic("AddRelation BktPath, Fld, TargetPath required: ", rel)
+	}
+	relationsLock.Lock()
+	relations = append(relations, rel)
+	relationsLock.Unlock()
+}
+
+// ClearRelations removes all relations added by AddRelation.
+func ClearRelations() {
+	relationsLock.Lock()
+	relations = nil
+	relationsLock.Unlock()
+}
+
+// samePath returns true if bucket paths a and b are equal.
+func samePath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// refPlan holds the changes a save makes to other recs because of relations.
+// It is built before anything is written (see planDeletes), so a violation leaves the db
+// and RecMap unchanged.
+type refPlan struct {
+	deletes map[string]map[string]bool // path key -> keys deleted by the save or by Cascade
+	puts    map[string]map[string]bool // path key -> keys written by the save
+	paths   map[string][]string        // path key -> bucket path
+	clears  []refClear                 // recs with a fld set to "" by SetEmpty
+}
+type refClear struct {
+	bktPath []string
+	key     string
+	fld     string
+}
+
+func newRefPlan() *refPlan {
+	return &refPlan{deletes: make(map[string]map[string]bool), puts: make(map[string]map[string]bool), paths: make(map[string][]string)}
+}
+
+// pathKey returns a map key for bktPath.
+func pathKey(bktPath []string) string {
+	return strings.Join(bktPath, "\x00")
+}
+
+// deleted returns true if key in bktPath is deleted by the plan.
+func (this *refPlan) deleted(bktPath []string, key string) bool {
+	return this.deletes[pathKey(bktPath)][key]
+}
+
+// addDeletes adds keys of bktPath to the plan, returning the keys not already in it.
+func (this *refPlan) addDeletes(bktPath []string, keys []string) []string {
+	pk := pathKey(bktPath)
+	if this.deletes[pk] == nil {
+		this.deletes[pk] = make(map[string]bool)
+		this.paths[pk] = bktPath
+	}
+	added := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !this.deletes[pk][key] {
+			this.deletes[pk][key] = true
+			added = append(added, key)
+		}
+	}
+	return added
+}
+
+// addPut adds key of bktPath, written by the save, to the plan.
+func (this *refPlan) addPut(bktPath []string, key string) {
+	pk := pathKey(bktPath)
+	if this.puts[pk] == nil {
+		this.puts[pk] = make(map[string]bool)
+	}
+	this.puts[pk][key] = true
+}
+
+// planDeletes adds keys being deleted from bktPath to the plan and applies OnDelete actions
+// of relations targeting bktPath. Each referencing bucket is scanned once for all keys.
+// Returns error if a Restrict relation is violated. Nothing is written.
+func planDeletes(tx *bolt.Tx, plan *refPlan, bktPath []string, keys []string) error {
+	return planRefDeletes(tx, plan, bktPath, plan.addDeletes(bktPath, keys))
+}
+
+// planRefDeletes applies OnDelete actions of relations targeting bktPath for keys already
+// added to the plan (see planDeletes).
+func planRefDeletes(tx *bolt.Tx, plan *refPlan, bktPath []string, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	relationsLock.RLock()
+	rels := make([]Relation, 0)
+	for _, rel := range relations {
+		if samePath(rel.TargetPath, bktPath) {
+			rels = append(rels, rel)
+		}
+	}
+	relationsLock.RUnlock()
+	deleted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		deleted[key] = true
+	}
+	for _, rel := range rels {
+		refs := make([]string, 0) // keys of recs referencing a deleted key
+		fldOnly := map[string]bool{rel.Fld: true}
+		cursor := OpenBucket(tx, rel.BktPath).Cursor()
+		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+			if v == nil || plan.deleted(rel.BktPath, string(k)) { // nested bucket or rec being deleted
+				continue
+			}
+			valMap := make(ValMap)
+			valMap.fromJsonFlds(v, fldOnly)
+			if !deleted[valMap[rel.Fld]] {
+				continue
+			}
+			if rel.OnDelete == Restrict {
+				return fmt.Errorf("cannot delete %s from %v, referenced by rec %s in %v", valMap[rel.Fld], bktPath, k, rel.BktPath)
+			}
+			refs = append(refs, string(k))
+		}
+		switch rel.OnDelete {
+		case Cascade:
+			if err := planDeletes(tx, plan, rel.BktPath, refs); err != nil {
+				return err
+			}
+		case SetEmpty:
+			for _, refKey := range refs {
+				plan.clears = append(plan.clears, refClear{rel.BktPath, refKey, rel.Fld})
+			}
+		}
+	}
+	return nil
+}
+
+// checkRefs returns error if a relation fld in vals does not contain the key of an existing rec
+// (one not deleted by plan) or of a rec written by the save.
+func checkRefs(tx *bolt.Tx, plan *refPlan, bktPath []string, key string, vals ValMap) error {
+	relationsLock.RLock()
+	defer relationsLock.RUnlock()
+	for _, rel := range relations {
+		val := vals[rel.Fld]
+		if !samePath(rel.BktPath, bktPath) || val == "" {
+			continue
+		}
+		if plan.puts[pathKey(rel.TargetPath)][val] {
+			continue
+		}
+		if OpenBucket(tx, rel.TargetPath).Get(bs(val)) == nil || plan.deleted(rel.TargetPath, val) {
+			return fmt.Errorf("rec %s in %v, fld %s: %s not found in %v", key, bktPath, rel.Fld, val, rel.TargetPath)
+		}
+	}
+	return nil
+}
+
+// apply writes the plan's Cascade deletes and SetEmpty changes. Keys of skipPaths are not deleted
+// (the saving Tables delete their own recs).
+func (this *refPlan) apply(tx *bolt.Tx, skipPaths ...[]string) error {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[pathKey(path)] = true
+	}
+	for pk, keys := range this.deletes {
+		if skip[pk] {
+			continue
+		}
+		bkt := OpenBucket(tx, this.paths[pk])
+		for key := range keys {
+			if err := bkt.Delete(bs(key)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, clear := range this.clears {
+		if this.deleted(clear.bktPath, clear.key) {
+			continue
+		}
+		bkt := OpenBucket(tx, clear.bktPath)
+		valMap := make(ValMap)
+		valMap.fromJson(bkt.Get(bs(clear.key)))
+		valMap[clear.fld] = ""
+		if err := bkt.Put(bs(clear.key), valMap.toJson()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Orphan is a rec whose relation fld contains a key not found in the relation's TargetPath.
+type Orphan struct {
+	BktPath []string
+	Key     string
+	Fld     string
+	Val     string // missing key
+}
+
+// IntegrityReport scans the db for recs violating relations added by AddRelation.
+func IntegrityReport() []Orphan {
+	orphans := make([]Orphan, 0)
+	relationsLock.RLock()
+	rels := append([]Relation(nil), relations...)
+	relationsLock.RUnlock()
+	db.View(func(tx *bolt.Tx) error {
+		for _, rel := range rels {
+			target := OpenBucket(tx, rel.TargetPath)
+			fldOnly := map[string]bool{rel.Fld: true}
+			cursor := OpenBucket(tx, rel.BktPath).Cursor()
+			for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+				if v == nil {
+					continue
+				}
+				valMap := make(ValMap)
+				valMap.fromJsonFlds(v, fldOnly)
+				val := valMap[rel.Fld]
+				if val != "" && target.Get(bs(val)) == nil {
+					orphans = append(orphans, Orphan{BktPath: rel.BktPath, Key: string(k), Fld: rel.Fld, Val: val})
+				}
+			}
+		}
+		return nil
+	})
+	return orphans
+}
@@ -0,0 +1,105 @@
+// These tests check relations between "authors", "books" and "reviews" buckets.
+
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"testing"
+)
+
+func TestRelations(t *testing.T) {
+	CreateBucket("authors")
+	CreateBucket("books")
+	CreateBucket("reviews")
+	defer ClearRelations()
+	AddRelation(Relation{BktPath: []string{"books"}, Fld: "authorId", TargetPath: []string{"authors"}, OnDelete: Cascade})
+	AddRelation(Relation{BktPath: []string{"reviews"}, Fld: "bookId", TargetPath: []string{"books"}, OnDelete: SetEmpty})
+
+	authors := NewTable(FldMap{"name": "str"}, NotShared, "authors")
+	authors.CreateRecMap()
+	authors.AddRec("a1", ValMap{"name": "Twain"})
+	authors.AddRec("a2", ValMap{"name": "Austen"})
+	books := NewTable(FldMap{"title": "str", "authorId": "str"}, NotShared, "books")
+	books.CreateRecMap()
+	books.AddRec("b1", ValMap{"title": "Huck Finn", "authorId": "a1"})
+	books.AddRec("b2", ValMap{"title": "Emma", "authorId": "a2"})
+	reviews := NewTable(FldMap{"bookId": "str", "stars": "int"}, NotShared, "reviews")
+	reviews.CreateRecMap()
+	reviews.AddRec("r1", ValMap{"bookId": "b1", "stars": "5"})
+	tx := StartDBWrite()
+	authors.Save(tx)
+	books.Save(tx)
+	reviews.Save(tx)
+	CommitDBWrite(tx)
+
+	books.AddRec("b3", ValMap{"title": "Unknown", "authorId": "a9"})
+	if _, err := books.SaveBatched(); err == nil {
+		t.Fatal("rec with missing target key was saved")
+	}
+	delete(books.RecMap, "b3")
+
+	authors.DeleteRec("a1")
+	tx = StartDBWrite()
+	authors.Save(tx)
+	CommitDBWrite(tx)
+	books.Load()
+	reviews.Load()
+	if books.GetRec("b1") != nil || books.GetRec("b2") == nil {
+		t.Fatal("cascade delete failed")
+	}
+	if rec := reviews.GetRec("r1"); rec == nil || rec.Get("bookId") != "" || rec.GetInt("stars") != 5 {
+		t.Fatal("set empty on delete failed")
+	}
+
+	ClearRelations()
+	AddRelation(Relation{BktPath: []string{"books"}, Fld: "authorId", TargetPath: []string{"authors"}}) // Restrict
+	authors.DeleteRec("a2")
+	authors.AddRec("a3", ValMap{"name": "Eliot"})
+	tx = StartDBWrite()
+	if _, err := authors.TrySave(tx); err == nil {
+		t.Error("restricted delete did not return error")
+	}
+	if authors.GetRec("a2").Vals["#delete"] != "1" || authors.GetRec("a3").Vals["#c"] != "1" {
+		t.Error("TrySave error changed RecMap")
+	}
+	CommitDBWrite(tx)
+	db.View(func(tx *bolt.Tx) error {
+		if OpenBucket(tx, []string{"authors"}).Get(bs("a3")) != nil {
+			t.Error("TrySave error wrote to db")
+		}
+		return nil
+	})
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				CommitDBWrite(tx)
+				t.Error("restricted delete did not panic")
+			}
+		}()
+		tx = StartDBWrite()
+		authors.Save(tx)
+	}()
+	delete(authors.RecMap, "a3")
+
+	// deleting the referencing rec earlier in the same transaction is not a violation
+	books.Load()
+	books.DeleteRec("b2")
+	tx = StartDBWrite()
+	books.Save(tx)
+	authors.DeleteRec("a2")
+	if _, err := authors.TrySave(tx); err != nil {
+		t.Error("delete after referencing rec deleted failed: ", err)
+	}
+	CommitDBWrite(tx)
+
+	if orphans := IntegrityReport(); len(orphans) != 0 {
+		t.Fatal("IntegrityReport found orphans: ", orphans)
+	}
+	db.Update(func(tx *bolt.Tx) error { // bypass Save's checks
+		return OpenBucket(tx, []string{"books"}).Put(bs("b4"), ValMap{"authorId": "a7"}.toJson())
+	})
+	orphans := IntegrityReport()
+	if len(orphans) != 1 || orphans[0].Key != "b4" || orphans[0].Val != "a7" {
+		t.Fatal("IntegrityReport wrong: ", orphans)
+	}
+}
@@ -0,0 +1,199 @@
+package bo
+
+import (
+	"bytes"
+	"github.com/boltdb/bolt"
+	"log"
+)
+
+// ScanOpts selects the db recs read by Table's Scan and Iterator methods.
+// If both Prefix and Start/End are set, keys must match both.
+// An empty Start begins with the 1st rec in the bucket, an empty End stops after the last rec.
+// If RenewAfter is greater than 0, the read transaction is replaced after that many recs,
+// so very long scans do not keep a single transaction open (see bolt docs on long read transactions).
+type ScanOpts struct {
+	Start      string
+	End        string
+	Prefix     string
+	Reverse    bool // read in descending key order
+	RenewAfter int  // number of recs read before read transaction is renewed, 0 = never
+}
+
+// Iterator reads db recs 1 at a time inside a read transaction.
+// Recs are not loaded into the Table's RecMap.
+// Close must be called if Next is not called until it returns false.
+type Iterator struct {
+	tbl     *Table
+	opts    ScanOpts
+	tx      *bolt.Tx
+	cursor  *bolt.Cursor
+	key     []byte // copy of current key, bolt's key is not valid after tx ends
+	val     []byte // only valid until Next is called
+	started bool
+	done    bool
+	count   int
+}
+
+// Iterator returns an Iterator for reading recs selected by opts.
+// See readme for an example.
+func (this *Table) Iterator(opts ScanOpts) *Iterator {
+	iter := &Iterator{tbl: this, opts: opts}
+	iter.begin()
+	return iter
+}
+
+// begin starts a read transaction and creates cursor for tbl's bucket.
+func (this *Iterator) begin() {
+	tx, err := db.Begin(false)
+	if err != nil {
+		log.Panic("Iterator read transaction failed, ", err)
+	}
+	this.tx = tx
+	this.cursor = this.tbl.openBucket(tx).Cursor()
+}
+
+// Next moves to the next rec, returning false when there are no more recs.
+func (this *Iterator) Next() bool {
+	if this.done {
+		return false
+	}
+	var k, v []byte
+	switch {
+	case !this.started:
+		this.started = true
+		k, v = this.first()
+	case this.opts.RenewAfter > 0 && this.count%this.opts.RenewAfter == 0:
+		k, v = this.renew()
+	case this.opts.Reverse:
+		k, v = this.cursor.Prev()
+	default:
+		k, v = this.cursor.Next()
+	}
+	if k == nil || !this.inBounds(k) {
+		this.Close()
+		return false
+	}
+	this.key = append(this.key[:0], k...)
+	this.val = v
+	this.count++
+	return true
+}
+
+// first positions cursor at the 1st rec to be read.
+func (this *Iterator) first() ([]byte, []byte) {
+	opts := this.opts
+	if !opts.Reverse {
+		lower := opts.Start // recs to be read are at or above this value
+		if opts.Prefix > lower {
+			lower = opts.Prefix
+		}
+		if lower == "" {
+			return this.cursor.First()
+		}
+		return this.cursor.Seek(bs(lower))
+	}
+	// upper bound is the lower of End (inclusive) and the end of Prefix (exclusive)
+	var upper []byte
+	inclusive := false
+	if opts.Prefix != "" {
+		upper = prefixEnd(bs(opts.Prefix)) // nil if prefix is all 0xff bytes
+	}
+	if opts.End != "" && (upper == nil || bytes.Compare(bs(opts.End), upper) < 0) {
+		upper, inclusive = bs(opts.End), true
+	}
+	if upper == nil {
+		return this.cursor.Last()
+	}
+	k, v := this.cursor.Seek(upper)
+	if k == nil {
+		return this.cursor.Last()
+	}
+	if inclusive && bytes.Equal(k, upper) {
+		return k, v
+	}
+	return this.cursor.Prev()
+}
+
+// renew replaces the read transaction, repositioning cursor after the current key.
+func (this *Iterator) renew() ([]byte, []byte) {
+	this.tx.Rollback()
+	this.begin()
+	k, v := this.cursor.Seek(this.key)
+	if this.opts.Reverse {
+		if k == nil {
+			return this.cursor.Last()
+		}
+		return this.cursor.Prev()
+	}
+	if k != nil && bytes.Equal(k, this.key) {
+		return this.cursor.Next()
+	}
+	return k, v
+}
+
+// inBounds returns true if k is inside the key selection.
+func (this *Iterator) inBounds(k []byte) bool {
+	opts := this.opts
+	if opts.Prefix != "" && !bytes.HasPrefix(k, bs(opts.Prefix)) {
+		return false
+	}
+	if opts.Start != "" && bytes.Compare(k, bs(opts.Start)) < 0 {
+		return false
+	}
+	return opts.End == "" || bytes.Compare(k, bs(opts.End)) <= 0
+}
+
+// IsBucket returns true if the current key is a nested bucket, not a rec.
+func (this *Iterator) IsBucket() bool {
+	return this.val == nil
+}
+
+// Key returns key of current rec.
+func (this *Iterator) Key() string {
+	return string(this.key)
+}
+
+// Rec returns current rec, decoded from db value (only Table's load flds if set, see SetLoadFlds).
+// The Rec belongs to the Iterator's Table, but is not added to its RecMap.
+func (this *Iterator) Rec() *Rec {
+	return this.tbl.loadedRec(string(this.key), this.val)
+}
+
+// Close ends the read transaction. It is safe to call more than once.
+func (this *Iterator) Close() {
+	if this.tx != nil {
+		this.tx.Rollback()
+		this.tx = nil
+	}
+	this.done = true
+}
+
+// Scan reads db recs selected by opts, calling fn for each one.
+// Recs are decoded 1 at a time and are not loaded into RecMap.
+// If fn returns false, the scan is stopped.
+// Returns number of recs passed to fn.
+func (this *Table) Scan(opts ScanOpts, fn func(key string, rec *Rec) bool) int {
+	iter := this.Iterator(opts)
+	defer iter.Close()
+	var count int
+	for iter.Next() {
+		count++
+		if !fn(iter.Key(), iter.Rec()) {
+			break
+		}
+	}
+	return count
+}
+
+// prefixEnd returns the smallest key greater than all keys beginning with prefix.
+// Returns nil if there is no such key (prefix is all 0xff bytes).
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// These tests read a "history" bucket using Scan and Iterator.
+
+package bo
+
+import (
+	"fmt"
+	"testing"
+)
+
+var historyFlds = FldMap{
+	"year": "str",
+	"n":    "int",
+}
+
+func TestScan(t *testing.T) {
+	CreateBucket("history")
+	tbl := NewTable(historyFlds, NotShared, "history")
+	tbl.CreateRecMap()
+	for _, year := range []string{"2015", "2016", "2017"} {
+		for i := 1; i <= 10; i++ {
+			key := fmt.Sprintf("%s-%03d", year, i)
+			tbl.AddRec(key, ValMap{"year": year, "n": IntToStr(int64(i))})
+		}
+	}
+	tx := StartDBWrite()
+	tbl.Save(tx)
+	CommitDBWrite(tx)
+
+	tbl = NewTable(historyFlds, NotShared, "history") // RecMap is never created
+	if count := tbl.Scan(ScanOpts{}, func(key string, rec *Rec) bool { return true }); count != 30 {
+		t.Fatal("Scan all count wrong: ", count)
+	}
+
+	keys := make([]string, 0)
+	tbl.Scan(ScanOpts{Prefix: "2016", Reverse: true}, func(key string, rec *Rec) bool {
+		if rec.Get("year") != "2016" {
+			t.Fatal("Scan prefix returned wrong rec: ", key)
+		}
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 10 || keys[0] != "2016-010" || keys[9] != "2016-001" {
+		t.Fatal("Scan reverse prefix wrong: ", keys)
+	}
+
+	keys = keys[:0]
+	tbl.Scan(ScanOpts{Start: "2015-009", End: "2016-002", Reverse: true}, func(key string, rec *Rec) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 4 || keys[0] != "2016-002" || keys[3] != "2015-009" {
+		t.Fatal("Scan reverse range wrong: ", keys)
+	}
+
+	keys = keys[:0]
+	tbl.Scan(ScanOpts{Prefix: "2016", End: "2017-001", Reverse: true}, func(key string, rec *Rec) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 10 || keys[0] != "2016-010" {
+		t.Fatal("Scan reverse prefix and end wrong: ", keys)
+	}
+	keys = keys[:0]
+	tbl.Scan(ScanOpts{Prefix: "2016", End: "2016-003", Reverse: true}, func(key string, rec *Rec) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 3 || keys[0] != "2016-003" {
+		t.Fatal("Scan reverse prefix and existing end wrong: ", keys)
+	}
+
+	count := tbl.Scan(ScanOpts{}, func(key string, rec *Rec) bool {
+		return rec.GetInt("n") < 5
+	})
+	if count != 5 {
+		t.Fatal("Scan early stop count wrong: ", count)
+	}
+}
+
+func TestIterator(t *testing.T) {
+	tbl := NewTable(historyFlds, NotShared, "history")
+	for _, reverse := range []bool{false, true} {
+		iter := tbl.Iterator(ScanOpts{RenewAfter: 7, Reverse: reverse})
+		var count int
+		var prevKey string
+		for iter.Next() {
+			key := iter.Key()
+			if count > 0 && (key > prevKey) == reverse {
+				t.Fatal("Iterator out of order: ", prevKey, " ", key)
+			}
+			prevKey = key
+			count++
+		}
+		iter.Close()
+		if count != 30 {
+			t.Fatal("Iterator with renew count wrong: ", count, " reverse: ", reverse)
+		}
+	}
+
+	iter := tbl.Iterator(ScanOpts{Start: "2017-005"})
+	defer iter.Close()
+	if !iter.Next() || iter.Key() != "2017-005" || iter.Rec().GetInt("n") != 5 {
+		t.Fatal("Iterator start failed")
+	}
+}
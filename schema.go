@@ -0,0 +1,157 @@
+package bo
+
+import (
+	"encoding/json"
+	"github.com/boltdb/bolt"
+	"log"
+	"strings"
+)
+
+// schemaKey returns the metadata key of bktPath's schema.
+func schemaKey(bktPath []string) []byte {
+	return bs(strings.Join(bktPath, "/"))
+}
+
+// RegisterSchema stores flds as the schema of bucket bktPath in the metadata bucket,
+// replacing any previous schema. Schemas are shown by Stats and tools reading the db.
+func RegisterSchema(flds FldMap, bktPath ...string) {
+	registerSchema(nil, flds, bktPath)
+}
+
+// registerSchema stores flds in the metadata bucket inside root (top level if root is nil),
+// bktPath is relative to root.
+func registerSchema(root []string, flds FldMap, bktPath []string) {
+	for fld, valType := range flds {
+		if strings.Index(validTypes, valType) == -1 {
+			log.Panic("RegisterSchema invalid type for fld ", fld, " - ", valType)
+		}
+	}
+	val, err := json.Marshal(flds)
+	if err != nil {
+		log.Panic("RegisterSchema failed, ", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return rootMetaBucket(tx, root, "schemas").Put(schemaKey(bktPath), val)
+	})
+	if err != nil {
+		log.Panic("RegisterSchema failed, ", err)
+	}
+}
+
+// schemaEntry is a schema found by pathSchemas.
+type schemaEntry struct {
+	root []string     // bucket containing the metadata bucket, empty for top level
+	bkt  *bolt.Bucket // "schemas" bucket
+	key  string
+	val  []byte
+}
+
+// pathSchemas returns the schemas registered for bktPath and its nested buckets,
+// in the metadata buckets of the top level and of every bucket above bktPath.
+func pathSchemas(tx *bolt.Tx, bktPath []string) []schemaEntry {
+	entries := make([]schemaEntry, 0)
+	var parent bucketParent = tx
+	for i, name := range bktPath {
+		if meta := parent.Bucket(bs(MetaBktName)); meta != nil {
+			if bkt := meta.Bucket(bs("schemas")); bkt != nil {
+				prefix := string(schemaKey(bktPath[i:]))
+				cursor := bkt.Cursor()
+				for k, v := cursor.Seek(bs(prefix)); k != nil && strings.HasPrefix(string(k), prefix); k, v = cursor.Next() {
+					if rest := string(k)[len(prefix):]; rest == "" || rest[0] == '/' {
+						entries = append(entries, schemaEntry{bktPath[:i], bkt, string(k), append([]byte(nil), v...)})
+					}
+				}
+			}
+		}
+		bkt := parent.Bucket(bs(name))
+		if bkt == nil {
+			break
+		}
+		parent = bkt
+	}
+	return entries
+}
+
+// deleteSchemas deletes the schemas registered for bktPath and its nested buckets.
+func deleteSchemas(tx *bolt.Tx, bktPath []string) error {
+	for _, entry := range pathSchemas(tx, bktPath) {
+		if err := entry.bkt.Delete(bs(entry.key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveSchemas copies the schemas registered for bucket from and its nested buckets to path to,
+// deleting them from from if move is true. A schema stays in the same metadata bucket if to is
+// inside the bucket containing it (ex. a namespace), else it is stored in the top level one.
+func moveSchemas(tx *bolt.Tx, from, to []string, move bool) error {
+	entries := pathSchemas(tx, from)
+	if move {
+		if err := deleteSchemas(tx, from); err != nil {
+			return err
+		}
+	}
+	for _, entry := range entries {
+		rest := entry.key[len(schemaKey(from[len(entry.root):])):]
+		root := entry.root
+		if len(to) <= len(root) || !samePath(root, to[:len(root)]) {
+			root = nil
+		}
+		if err := rootMetaBucket(tx, root, "schemas").Put(bs(string(schemaKey(to[len(root):]))+rest), entry.val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterSchema registers the Table's Flds as the schema of its bucket, see RegisterSchema func.
+// Tables created by Namespace.NewTable register in the namespace (see Namespace.RegisterSchema).
+func (this *Table) RegisterSchema() {
+	if this.nsRoot != nil {
+		this.checkNamespace()
+		registerSchema(this.nsRoot, this.Flds, this.BktPath[len(this.nsRoot):])
+		return
+	}
+	RegisterSchema(this.Flds, this.BktPath...)
+}
+
+// GetSchema returns the schema registered for bucket bktPath, nil if none.
+func GetSchema(bktPath ...string) FldMap {
+	var flds FldMap
+	db.View(func(tx *bolt.Tx) error {
+		flds = txSchema(tx, bktPath)
+		return nil
+	})
+	return flds
+}
+
+// txSchema returns the schema registered for bktPath, nil if none.
+// The metadata buckets of the top level and of every bucket above bktPath (namespace roots)
+// are checked, the one closest to bktPath is used.
+func txSchema(tx *bolt.Tx, bktPath []string) FldMap {
+	var val []byte
+	var parent bucketParent = tx
+	for i, name := range bktPath {
+		if meta := parent.Bucket(bs(MetaBktName)); meta != nil {
+			if bkt := meta.Bucket(bs("schemas")); bkt != nil {
+				if v := bkt.Get(schemaKey(bktPath[i:])); v != nil {
+					val = v
+				}
+			}
+		}
+		bkt := parent.Bucket(bs(name))
+		if bkt == nil {
+			break
+		}
+		parent = bkt
+	}
+	if val == nil {
+		return nil
+	}
+	var flds FldMap
+	if err := json.Unmarshal(val, &flds); err != nil {
+		log.Panic("GetSchema invalid schema, ", err, bktPath)
+	}
+	return flds
+}
@@ -0,0 +1,171 @@
+package bo
+
+import (
+	"fmt"
+	"github.com/boltdb/bolt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// SeqOpts.Reset values, see SeqOpts.
+const (
+	ResetNever    = ""
+	ResetDaily    = "daily"
+	ResetMonthly  = "monthly"
+	ResetYearly   = "yearly"
+	ResetByParent = "parent"
+)
+
+// seqClock returns the time used by daily, monthly, yearly resets (replaced in tests).
+var seqClock = time.Now
+
+// SeqOpts are the options of a NamedSeq.
+type SeqOpts struct {
+	Width  int    // digits in number, zero prefixed, 0 = no padding
+	Start  int64  // 1st value, default 1
+	Step   int64  // increment, default 1
+	Prefix string // added to beginning of every value
+	// Reset restarts numbering at Start for every day, month, year or parent key.
+	// The period ("20240305", "202403", "2024") or parent key follows Prefix in returned values,
+	// ex. Prefix "INV-", ResetYearly, Width 5 returns "INV-202400001".
+	Reset string
+}
+
+// NamedSeq is a persistent sequence stored in the metadata bucket (see MetaBktName).
+// It is safe for concurrent use (every value is created in a write transaction)
+// and can be used inside an open write transaction.
+// NamedSeq is a KeyGenerator (except with ResetByParent), see Table.SetKeyGenerator.
+type NamedSeq struct {
+	Name string
+	SeqOpts
+	root []string // Namespace root, nil if not in a Namespace
+}
+
+// DefineSeq returns a NamedSeq with name and opts.
+// Nothing is written to the db until a value is requested.
+// The same name should always be defined with the same opts.
+func DefineSeq(name string, opts SeqOpts) *NamedSeq {
+	if name == "" {
+		log.Panic("DefineSeq name required")
+	}
+	switch opts.Reset {
+	case ResetNever, ResetDaily, ResetMonthly, ResetYearly, ResetByParent:
+	default:
+		log.Panic("DefineSeq invalid Reset: ", opts.Reset)
+	}
+	if opts.Start == 0 {
+		opts.Start = 1
+	}
+	if opts.Step == 0 {
+		opts.Step = 1
+	}
+	return &NamedSeq{Name: name, SeqOpts: opts}
+}
+
+// bucket returns the bucket containing the sequence's values (nil if missing and tx is read only).
+func (this *NamedSeq) bucket(tx *bolt.Tx) *bolt.Bucket {
+	return rootMetaBucket(tx, this.root, "sequences", this.Name)
+}
+
+// periodKey returns the db key of period (bolt keys cannot be empty).
+func periodKey(period string) []byte {
+	if period == "" {
+		return bs("-")
+	}
+	return bs(period)
+}
+
+// period returns the reset period, parentKey is required if Reset is ResetByParent.
+func (this *NamedSeq) period(parentKey []string) string {
+	switch this.Reset {
+	case ResetDaily:
+		return seqClock().Format("20060102")
+	case ResetMonthly:
+		return seqClock().Format("200601")
+	case ResetYearly:
+		return seqClock().Format("2006")
+	case ResetByParent:
+		if len(parentKey) == 0 || parentKey[0] == "" {
+			log.Panic("NamedSeq ", this.Name, " requires parent key")
+		}
+		return parentKey[0]
+	}
+	return ""
+}
+
+// Next returns the next value. Tx is the open write transaction or nil.
+// ParentKey is only used (and required) if Reset is ResetByParent.
+// If tx is rolled back, the value will be returned again.
+func (this *NamedSeq) Next(tx *bolt.Tx, parentKey ...string) string {
+	return this.NextN(tx, 1, parentKey...)[0]
+}
+
+// NextN returns the next count values, see Next. Returns no values if count is less than 1.
+func (this *NamedSeq) NextN(tx *bolt.Tx, count int, parentKey ...string) []string {
+	if count < 1 {
+		return []string{}
+	}
+	period := this.period(parentKey)
+	vals := make([]string, count)
+	writeTx(tx, func(tx *bolt.Tx) error {
+		bkt := this.bucket(tx)
+		n := this.current(bkt, period)
+		started := bkt.Get(periodKey(period)) != nil
+		for i := range vals {
+			if i == 0 && !started {
+				n = this.Start
+			} else {
+				n += this.Step
+			}
+			vals[i] = this.format(period, n)
+		}
+		return bkt.Put(periodKey(period), bs(strconv.FormatInt(n, 10)))
+	})
+	return vals
+}
+
+// current returns the last value of period stored in bkt, 0 if none.
+func (this *NamedSeq) current(bkt *bolt.Bucket, period string) int64 {
+	if bkt == nil {
+		return 0
+	}
+	val := bkt.Get(periodKey(period))
+	if val == nil {
+		return 0
+	}
+	return StrToInt(string(val))
+}
+
+// Current returns the last value returned by Next (0 if none), without the prefix and period.
+func (this *NamedSeq) Current(parentKey ...string) int64 {
+	period := this.period(parentKey)
+	var n int64
+	db.View(func(tx *bolt.Tx) error {
+		n = this.current(this.bucket(tx), period)
+		return nil
+	})
+	return n
+}
+
+// Set changes the last value, the next value will be val + Step.
+func (this *NamedSeq) Set(tx *bolt.Tx, val int64, parentKey ...string) {
+	period := this.period(parentKey)
+	writeTx(tx, func(tx *bolt.Tx) error {
+		return this.bucket(tx).Put(periodKey(period), bs(strconv.FormatInt(val, 10)))
+	})
+}
+
+// format returns the sequence value for number n.
+func (this *NamedSeq) format(period string, n int64) string {
+	num := fmt.Sprintf("%0*d", this.Width, n)
+	if this.Width > 0 && (len(num) > this.Width || n < 0) {
+		log.Panic("NamedSeq ", this.Name, " value ", n, " exceeds width ", this.Width)
+	}
+	return this.Prefix + period + num
+}
+
+// NextKey implements KeyGenerator.
+func (this *NamedSeq) NextKey(tx *bolt.Tx, tbl *Table) string {
+	return this.Next(tx)
+}
@@ -0,0 +1,73 @@
+// These tests check persistent named sequences.
+
+package bo
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNamedSeq(t *testing.T) {
+	orderNo := DefineSeq("orderNo", SeqOpts{Width: 6, Start: 1000, Step: 10, Prefix: "ORD"})
+	if vals := orderNo.NextN(nil, 0); len(vals) != 0 || len(orderNo.NextN(nil, -1)) != 0 {
+		t.Fatal("NextN 0 or negative count returned values")
+	}
+	if val := orderNo.Next(nil); val != "ORD001000" {
+		t.Fatal("1st value wrong: ", val)
+	}
+	tx := StartDBWrite() // usable inside an open write transaction
+	vals := orderNo.NextN(tx, 2)
+	CommitDBWrite(tx)
+	if vals[0] != "ORD001010" || vals[1] != "ORD001020" || orderNo.Current() != 1020 {
+		t.Fatal("NextN wrong: ", vals)
+	}
+
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	seen := make(map[string]bool)
+	counter := DefineSeq("counter", SeqOpts{})
+	for g := 0; g < 5; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				val := counter.Next(nil)
+				lock.Lock()
+				seen[val] = true
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 100 || counter.Current() != 100 {
+		t.Fatal("concurrent values not unique: ", len(seen), counter.Current())
+	}
+
+	defer func() { seqClock = time.Now }()
+	seqClock = func() time.Time { return time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC) }
+	invoice := DefineSeq("invoice", SeqOpts{Width: 4, Prefix: "INV-", Reset: ResetYearly})
+	invoice.Next(nil)
+	if val := invoice.Next(nil); val != "INV-20240002" {
+		t.Fatal("yearly value wrong: ", val)
+	}
+	seqClock = func() time.Time { return time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC) }
+	if val := invoice.Next(nil); val != "INV-20250001" {
+		t.Fatal("yearly reset failed: ", val)
+	}
+
+	line := DefineSeq("line", SeqOpts{Width: 2, Reset: ResetByParent})
+	line.Next(nil, "A")
+	line.Set(nil, 98, "B")
+	if a, b := line.Next(nil, "A"), line.Next(nil, "B"); a != "A02" || b != "B99" {
+		t.Fatal("parent reset wrong: ", a, b)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("width overflow did not panic")
+			}
+		}()
+		line.Next(nil, "B")
+	}()
+}
@@ -0,0 +1,284 @@
+package bo
+
+import (
+	"fmt"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"log"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// SortFuncs contains custom comparison funcs that can be used as sortBy options.
+// fn returns -1 if a sorts before b, 1 if a sorts after b, 0 if equal. a & b are stored string values.
+// The map key is used as the option name, ex. CreateOrderBy("byCode", "code:myFunc").
+var SortFuncs = map[string]func(a, b string) int{}
+
+// sortSpec is a parsed sortBy value, ex. "name:i:last".
+type sortSpec struct {
+	fld      string
+	valType  string
+	desc     bool
+	fold     bool   // case insensitive
+	noAccent bool   // accents on latin letters ignored, then case insensitive
+	natural  bool   // digits compared as numbers, "item2" < "item10"
+	missing  string // "first", "last" or "" (missing values sort as empty or zero)
+	fn       func(a, b string) int
+	locale   string    // language tag of collation, ex. "sv", "" if none
+	collator *collator // set from locale and fold, noAccent, natural
+}
+
+// collator is a collate.Collator shared by sortSpecs with the same language and options.
+// A Collator is not safe for concurrent use, so it is locked.
+type collator struct {
+	lock sync.Mutex
+	coll *collate.Collator
+	buf  collate.Buffer
+}
+
+var collators = make(map[string]*collator)
+var collatorsLock sync.Mutex
+
+// getCollator returns the collator for tag (ex. "de", "sv", "fr-CA") and options.
+// Returns nil if tag is not a valid language tag.
+func getCollator(tag string, fold, noAccent, natural bool) *collator {
+	lang, err := language.Parse(tag)
+	if err != nil {
+		return nil
+	}
+	opts := make([]collate.Option, 0)
+	if fold || noAccent {
+		opts = append(opts, collate.IgnoreCase)
+	}
+	if noAccent {
+		opts = append(opts, collate.IgnoreDiacritics)
+	}
+	if natural {
+		opts = append(opts, collate.Numeric)
+	}
+	id := fmt.Sprint(lang, fold || noAccent, noAccent, natural)
+	collatorsLock.Lock()
+	defer collatorsLock.Unlock()
+	if c, found := collators[id]; found {
+		return c
+	}
+	c := &collator{coll: collate.New(lang, opts...)}
+	collators[id] = c
+	return c
+}
+
+// key returns the collation key of s, comparing keys bytewise gives the collation order.
+func (this *collator) key(s string) string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	key := string(this.coll.KeyFromString(&this.buf, s))
+	this.buf.Reset()
+	return key
+}
+
+// parseSortBy converts sortBy values to sortSpecs.
+// A sortBy value is a fld name followed by options, each preceded by ":".
+// Fld names containing ":" are supported, the longest prefix that is a fld name and is
+// followed only by valid options is used.
+func (this *Table) parseSortBy(sortBy []string) []sortSpec {
+	specs := make([]sortSpec, len(sortBy))
+	for i, sortFld := range sortBy {
+		parts := strings.Split(sortFld, ":")
+		found := false
+		for n := len(parts); n > 0 && !found; n-- {
+			fld := strings.Join(parts[:n], ":")
+			if _, ok := this.Flds[fld]; !ok {
+				continue
+			}
+			specs[i], found = parseSortOpts(fld, this.Flds[fld], parts[n:])
+		}
+		if !found {
+			log.Panic("invalid sortBy value: ", sortFld)
+		}
+	}
+	return specs
+}
+
+// parseSortOpts returns sortSpec for fld using opts. Returns false if an opt is not valid.
+func parseSortOpts(fld, valType string, opts []string) (sortSpec, bool) {
+	spec := sortSpec{fld: fld, valType: valType}
+	for _, opt := range opts {
+		switch opt {
+		case "a", "asc":
+			spec.desc = false
+		case "d", "desc":
+			spec.desc = true
+		case "i", "nocase":
+			spec.fold = true
+		case "u", "unaccent":
+			spec.noAccent = true
+		case "n", "natural":
+			spec.natural = true
+		case "first", "last":
+			spec.missing = opt
+		default:
+			if tag, found := cutPrefix(opt, "locale="); found {
+				spec.locale = tag
+				continue
+			}
+			fn, found := SortFuncs[opt]
+			if !found {
+				return spec, false
+			}
+			spec.fn = fn
+		}
+	}
+	if spec.locale != "" {
+		if spec.collator = getCollator(spec.locale, spec.fold, spec.noAccent, spec.natural); spec.collator == nil {
+			return spec, false
+		}
+	}
+	return spec, true
+}
+
+// cutPrefix returns s without prefix and true if s begins with prefix.
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
+
+// newSortRec returns sortRec containing rec's values for flds in specs.
+func (this *Table) newSortRec(key string, rec Rec, specs []sortSpec) *sortRec {
+	srtRec := &sortRec{
+		recKey: key,
+		vals:   make([]sortVal, len(specs)),
+	}
+	for i := range specs {
+		spec := &specs[i]
+		srtVal := &srtRec.vals[i]
+		srtVal.spec = spec
+		val, found := rec.Vals[spec.fld]
+		srtVal.missing = !found || val == ""
+		switch {
+		case spec.fn != nil:
+			srtVal.val = val
+		case spec.valType == "int":
+			srtVal.val = rec.GetInt(spec.fld)
+		case spec.valType == "float":
+			srtVal.val = rec.GetFloat(spec.fld)
+		case spec.valType == "bool":
+			srtVal.val = rec.GetBool(spec.fld)
+		case spec.collator != nil:
+			srtVal.val = spec.collator.key(val)
+		case spec.noAccent:
+			srtVal.val = foldAccents(strings.ToLower(val))
+		case spec.fold:
+			srtVal.val = strings.ToLower(val)
+		default: // date, dateTime work using string
+			srtVal.val = val
+		}
+	}
+	return srtRec
+}
+
+// compare returns -1 if a < b, 1 if a > b, 0 if equal. Direction is not applied.
+func (this *sortSpec) compare(a, b interface{}) int {
+	switch vala := a.(type) {
+	case int64:
+		valb := b.(int64)
+		if vala < valb {
+			return -1
+		} else if vala > valb {
+			return 1
+		}
+	case float64:
+		valb := b.(float64)
+		if vala < valb {
+			return -1
+		} else if vala > valb {
+			return 1
+		}
+	case bool: // false before true
+		valb := b.(bool)
+		if !vala && valb {
+			return -1
+		} else if vala && !valb {
+			return 1
+		}
+	case string:
+		valb := b.(string)
+		if this.fn != nil {
+			return this.fn(vala, valb)
+		}
+		if this.natural && this.collator == nil { // collator handles natural (collate.Numeric)
+			return compareNatural(vala, valb)
+		}
+		return strings.Compare(vala, valb)
+	}
+	return 0
+}
+
+// compareNatural compares strings, treating each run of digits as a number.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		digitsA, digitsB := isDigit(a[0]), isDigit(b[0])
+		if digitsA && digitsB {
+			numA, restA := splitRun(a, true)
+			numB, restB := splitRun(b, true)
+			numA, numB = strings.TrimLeft(numA, "0"), strings.TrimLeft(numB, "0")
+			if len(numA) != len(numB) {
+				if len(numA) < len(numB) {
+					return -1
+				}
+				return 1
+			}
+			if result := strings.Compare(numA, numB); result != 0 {
+				return result
+			}
+			a, b = restA, restB
+			continue
+		}
+		if digitsA != digitsB {
+			return strings.Compare(a[:1], b[:1])
+		}
+		txtA, restA := splitRun(a, false)
+		txtB, restB := splitRun(b, false)
+		if result := strings.Compare(txtA, txtB); result != 0 {
+			return result
+		}
+		a, b = restA, restB
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// splitRun returns leading run of digits (or non digits) in s and the remainder.
+func splitRun(s string, digits bool) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) == digits {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+var accented = []rune("àáâãäåçèéêëìíîïñòóôõöøùúûüýÿ")
+var unaccented = []rune("aaaaaaceeeeiiiinoooooouuuuyy")
+
+// foldAccents replaces accented latin letters with the unaccented letter (lower case only).
+// Used by the "unaccent" sort option, so "émile" sorts with "emile", before "fred".
+// This is a fixed table, not locale aware collation (ex. "ä" sorts as "a", also in Swedish),
+// the "locale" option uses collation rules of a language.
+func foldAccents(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII {
+			return r
+		}
+		for i, a := range accented {
+			if r == a {
+				return unaccented[i]
+			}
+		}
+		return r
+	}, s)
+}
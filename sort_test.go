@@ -0,0 +1,92 @@
+// These tests check sortBy options used by CreateOrderBy.
+
+package bo
+
+import (
+	"testing"
+)
+
+func TestSortOptions(t *testing.T) {
+	tbl := NewTable(FldMap{
+		"name":   "str",
+		"item":   "str",
+		"active": "bool",
+		"a:dog":  "int", // fld name containing ":d"
+	}, NotShared)
+	tbl.CreateRecMap()
+	tbl.AddRec("k1", ValMap{"name": "bob", "item": "item10", "active": "true", "a:dog": "3"})
+	tbl.AddRec("k2", ValMap{"name": "Ann", "item": "item2", "active": "false", "a:dog": "1"})
+	tbl.AddRec("k3", ValMap{"name": "Émile", "item": "item1", "active": "true", "a:dog": "2"})
+	tbl.AddRec("k4", ValMap{"item": "item2", "active": "false", "a:dog": "2"})
+
+	tbl.CreateOrderBy("byName", "name")
+	checkOrder(t, tbl, "byName", "k4", "k2", "k1", "k3")
+	tbl.CreateOrderBy("byNameNoCase", "name:i:last")
+	checkOrder(t, tbl, "byNameNoCase", "k2", "k1", "k3", "k4")
+	tbl.CreateOrderBy("byNameUnaccent", "name:u:d:first")
+	checkOrder(t, tbl, "byNameUnaccent", "k4", "k3", "k1", "k2")
+	tbl.CreateOrderBy("byItem", "item:n")
+	checkOrder(t, tbl, "byItem", "k3", "k2", "k4", "k1")
+	tbl.CreateOrderBy("byActive", "active:d", "a:dog")
+	checkOrder(t, tbl, "byActive", "k3", "k1", "k2", "k4")
+	tbl.CreateOrderBy("byDog", "a:dog:desc")
+	checkOrder(t, tbl, "byDog", "k1", "k3", "k4", "k2") // equal values in key order
+
+	SortFuncs["byLen"] = func(a, b string) int {
+		return len(a) - len(b)
+	}
+	defer delete(SortFuncs, "byLen")
+	tbl.CreateOrderBy("byNameLen", "name:byLen:last")
+	checkOrder(t, tbl, "byNameLen", "k1", "k2", "k3", "k4")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("invalid sort option did not panic")
+		}
+	}()
+	tbl.CreateOrderBy("bad", "name:x")
+}
+
+func TestCompareNatural(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"item2", "item10", -1},
+		{"item010", "item10", 0},
+		{"a1b2", "a1b10", -1},
+		{"b", "a10", 1},
+		{"10", "9", 1},
+		{"item", "item1", -1},
+	}
+	for _, test := range tests {
+		if got := compareNatural(test.a, test.b); got != test.want {
+			t.Error("compareNatural(", test.a, ", ", test.b, ") = ", got)
+		}
+	}
+}
+
+func TestSortLocale(t *testing.T) {
+	tbl := NewTable(FldMap{"name": "str"}, NotShared)
+	tbl.CreateRecMap()
+	tbl.AddRec("k1", ValMap{"name": "zebra"})
+	tbl.AddRec("k2", ValMap{"name": "öl"})
+	tbl.AddRec("k3", ValMap{"name": "ost"})
+	tbl.AddRec("k4", ValMap{"name": "Apa"})
+	tbl.AddRec("k5", ValMap{"name": "item10"})
+	tbl.AddRec("k6", ValMap{"name": "item2"})
+	tbl.CreateOrderBy("bySwedish", "name:locale=sv") // ö is a letter after z
+	checkOrder(t, tbl, "bySwedish", "k4", "k5", "k6", "k3", "k1", "k2")
+	tbl.CreateOrderBy("byGerman", "name:locale=de:n:d") // ö sorts with o
+	checkOrder(t, tbl, "byGerman", "k1", "k3", "k2", "k5", "k6", "k4")
+	tbl.CreateLiveOrderBy("bySwedishLive", "name:locale=sv")
+	tbl.GetRec("k4").Set("name", "ärm")
+	checkOrder(t, tbl, "bySwedishLive", "k5", "k6", "k3", "k1", "k4", "k2")
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("invalid locale did not panic")
+		}
+	}()
+	tbl.CreateOrderBy("bad", "name:locale=not a tag")
+}
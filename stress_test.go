@@ -125,3 +125,60 @@ func TestStress2(t *testing.T) {
 		t.Fatal("stress2 tot wrong, should be 12300, but is ", tot)
 	}
 }
+
+func TestStress3(t *testing.T) {
+	CreateBucket("stress3")
+	var flds = FldMap{
+		"id":    "str",
+		"date":  "date",
+		"amt":   "float",
+		"count": "int",
+		"note":  "str",
+	}
+	tbl := NewTable(flds, NotShared, "stress3")
+	tbl.CreateRecMap()
+	keys := tbl.GetNextKeys(20000)
+	for i := 0; i < len(keys); i++ {
+		tbl.AddRec(keys[i], ValMap{
+			"id":    keys[i],
+			"date":  DateToStr(time.Now()),
+			"amt":   FloatToStr(1.11),
+			"count": IntToStr(123),
+			"note":  "a note long enough to make decoding take some time",
+		})
+	}
+	tx := StartDBWrite()
+	tbl.Save(tx)
+	CommitDBWrite(tx)
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		tbl.Load()
+	}
+	fmt.Println("stress3 sequential load elapsed: ", time.Now().Sub(start))
+	seqKeys := tbl.OrderBy["byKey"]
+
+	tbl.SetLoadWorkers(4)
+	start = time.Now()
+	for i := 0; i < 10; i++ {
+		tbl.Load()
+	}
+	fmt.Println("stress3 parallel load (4 workers) elapsed: ", time.Now().Sub(start))
+
+	parKeys := tbl.OrderBy["byKey"]
+	if len(tbl.RecMap) != len(keys) || len(parKeys) != len(seqKeys) {
+		t.Fatal("stress3 parallel load count wrong: ", len(tbl.RecMap), len(parKeys))
+	}
+	for i := range seqKeys {
+		if parKeys[i] != seqKeys[i] {
+			t.Fatal("stress3 parallel byKey out of order at ", i)
+		}
+	}
+	var tot int64
+	tbl.Loop(func(key string, rec *Rec) {
+		tot += rec.GetInt("count")
+	})
+	if tot != 123*int64(len(keys)) {
+		t.Fatal("stress3 tot wrong: ", tot)
+	}
+}
@@ -1,7 +1,6 @@
 package bo
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/boltdb/bolt"
 	"log"
@@ -25,6 +24,21 @@ type Table struct {
 	Flds    FldMap              // used to validate fldName and type for sorting
 	RecMap  map[string]*Rec     // key is record's database key
 	OrderBy map[string][]string // key indicates field order (ex. partno)
+	// MergeSave, if true, Save merges only changed flds into the current db rec
+	// instead of replacing the whole rec.
+	MergeSave bool
+	// MergePolicy determines what Merge load methods do with a rec in RecMap that has
+	// unsaved changes when the same rec is read from the db (KeepLocal, Overwrite, ConflictError).
+	MergePolicy string
+	// KeyGen creates keys returned by NewKey, default is SequenceKeys (see SetKeyGenerator).
+	KeyGen      KeyGenerator
+	sels        []selection           // selections loaded since RecMap was created, used by Refresh
+	orderSpecs  map[string][]string   // sortBy values used by CreateOrderBy, key is orderByName
+	page        pageInfo              // used by LoadPage
+	loadFlds    map[string]bool       // if not nil, only these flds are loaded (see SetLoadFlds)
+	loadWorkers int                   // number of goroutines decoding recs (see SetLoadWorkers)
+	liveOrders  map[string]*liveOrder // OrderBy entries kept in order (see CreateLiveOrderBy)
+	nsRoot      []string              // if not nil, BktPath must be inside this bucket (see Namespace)
 }
 
 // StartRead sets Read Lock on table if table is shared.
@@ -61,81 +75,121 @@ func (this *Table) GetRec(key string) *Rec {
 	return rec
 }
 
+// newRec creates a Rec belonging to this table with change tracking turned on.
+func (this *Table) newRec(key string, valMap ValMap) *Rec {
+	return &Rec{
+		Tbl:  this,
+		Vals: valMap,
+		chg:  &recChanges{key: key, orig: make(map[string]origVal)},
+	}
+}
+
+// loadedRec creates a Rec from db value v, decoding only LoadFlds if set.
+func (this *Table) loadedRec(key string, v []byte) *Rec {
+	valMap := make(ValMap)
+	valMap.fromJsonFlds(v, this.loadFlds)
+	rec := this.newRec(key, valMap)
+	rec.chg.partial = this.loadFlds != nil
+	return rec
+}
+
+// SetLoadFlds limits the flds decoded by Load, Merge, LoadPage, Scan methods to flds.
+// Recs loaded while set are partial, Save merges their changes into the db rec,
+// so flds that were not loaded are not lost.
+// Calling with no flds turns off the limit (all flds are loaded).
+func (this *Table) SetLoadFlds(flds ...string) {
+	if len(flds) == 0 {
+		this.loadFlds = nil
+		return
+	}
+	this.loadFlds = make(map[string]bool)
+	for _, fld := range flds {
+		if _, found := this.Flds[fld]; !found {
+			log.Panic("SetLoadFlds invalid fld: ", fld)
+		}
+		this.loadFlds[fld] = true
+	}
+}
+
 // AddRec adds entry to table's RecMap, optional valMap sets Rec values.
 func (this *Table) AddRec(key string, valMap ...ValMap) *Rec {
+	var rec *Rec
 	if len(valMap) > 0 {
-		this.RecMap[key] = &Rec{Tbl: this, Vals: valMap[0]}
+		rec = this.newRec(key, valMap[0])
 	} else {
-		this.RecMap[key] = &Rec{Tbl: this, Vals: make(ValMap)}
+		rec = this.newRec(key, make(ValMap))
 	}
-	this.RecMap[key].Vals["#c"] = "1" // turn on change flag for Save method
-	return this.RecMap[key]
+	rec.chg.added = true
+	rec.Vals["#c"] = "1" // turn on change flag for Save method
+	this.liveChange(key, *rec, "", func() {
+		this.RecMap[key] = rec
+	})
+	return rec
 }
 
 // DeleteRec marks rec for deletion, when Save method is executed.
 func (this *Table) DeleteRec(key string) {
-	this.RecMap[key].Vals["#delete"] = "1"
+	rec := this.RecMap[key]
+	this.liveChange(key, *rec, "", func() {
+		rec.Vals["#delete"] = "1"
+	})
 }
 
 // GetNetKey returns bucket's NextSequence value as a zero prefixed string "00012".
+// Panics if the value does not fit KeySize (the sequence is not incremented).
 func (this *Table) GetNextKey() string {
-	bktPath := this.BktPath
-	var nextKey uint64
-	db.Update(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, bktPath)
-		nextKey, _ = bkt.NextSequence()
-		return nil
-	})
-	return fmt.Sprintf(this.KeySize, nextKey)
+	return this.GetNextKeys(1)[0]
 }
 
+// GetNextKeys returns count keys, see GetNextKey.
 func (this *Table) GetNextKeys(count int) []string {
-	bktPath := this.BktPath
 	keys := make([]string, count)
-	var nextKey uint64
-	db.Update(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, bktPath)
+	err := db.Update(func(tx *bolt.Tx) error {
+		bkt := this.openBucket(tx)
 		for i := 0; i < count; i++ {
-			nextKey, _ = bkt.NextSequence()
-			keys[i] = fmt.Sprintf(this.KeySize, nextKey)
+			nextKey, _ := bkt.NextSequence()
+			key, err := this.formatKey(nextKey)
+			if err != nil {
+				return err
+			}
+			keys[i] = key
 		}
 		return nil
 	})
+	if err != nil {
+		log.Panic("GetNextKey failed, ", err)
+	}
 	return keys
 }
 
+// formatKey returns n formatted with KeySize.
+// Returns error if the result is longer than the width in KeySize, keys would no longer sort in order.
+func (this *Table) formatKey(n uint64) (string, error) {
+	key := fmt.Sprintf(this.KeySize, n)
+	var width int
+	if _, err := fmt.Sscanf(this.KeySize, "%%0%dd", &width); err == nil && len(key) > width {
+		return "", fmt.Errorf("key %s exceeds KeySize %s, bkt: %v", key, this.KeySize, this.BktPath)
+	}
+	return key, nil
+}
+
 // CreateOrderBy creates slice of rec key values in sorted order.
 // The orderByName is used to reference the result. Ex: tbl.OrderBy[orderByName]
-// The sortBy values are names of fields to be sorted.
-// If a sortBy field name ends with ":d" or ":desc", this fld will be sorted in descending order
+// The sortBy values are names of fields to be sorted, optionally followed by sort options.
+// Ex: "amt:d" sorts amt in descending order, "name:i:last" sorts name ignoring case with
+// missing names last (see readme, More on Sorting, for all options).
+// Recs with equal sort values are in key order.
 func (this *Table) CreateOrderBy(orderByName string, sortBy ...string) {
 	this.StartWrite()
+	if this.orderSpecs == nil {
+		this.orderSpecs = make(map[string][]string)
+	}
+	this.orderSpecs[orderByName] = sortBy
+	delete(this.liveOrders, orderByName) // replaces live order with same name
+	specs := this.parseSortBy(sortBy)
 	sorted := make(sortRecs, 0, len(this.RecMap))
 	for key, rec := range this.RecMap {
-		srtRec := &sortRec{
-			recKey: key,
-			vals:   make([]sortVal, len(sortBy)),
-		}
-		for i, fldName := range sortBy {
-			sepNdx := strings.Index(fldName, ":d") // look for descending flag
-			if sepNdx > -1 {
-				fldName = fldName[:sepNdx]
-				srtRec.vals[i].direction = "desc"
-			} else {
-				srtRec.vals[i].direction = "asc"
-			}
-			valType := this.Flds[fldName]
-			srtRec.vals[i].valType = valType
-			switch valType {
-			case "int":
-				srtRec.vals[i].val = rec.GetInt(fldName)
-			case "float":
-				srtRec.vals[i].val = rec.GetFloat(fldName)
-			default:
-				srtRec.vals[i].val = rec.Get(fldName)
-			}
-		}
-		sorted = append(sorted, srtRec)
+		sorted = append(sorted, this.newSortRec(key, *rec, specs))
 	}
 	sort.Sort(sorted)
 	this.OrderBy[orderByName] = make([]string, len(sorted))
@@ -149,114 +203,33 @@ func (this *Table) CreateOrderBy(orderByName string, sortBy ...string) {
 // RecMap is recreated, so existing entries are lost
 // If loading from a nested bucket, specify path to it
 func (this *Table) Load() int {
-	this.StartWrite()
-	this.RecMap = make(map[string]*Rec)
-	this.OrderBy = make(map[string][]string)
-	keys := make([]string, 0, 100)
-	db.View(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, this.BktPath)
-		cursor := bkt.Cursor()
-		for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
-			valMap := make(ValMap)
-			valMap.fromJson(v)
-			key := string(k)
-			this.RecMap[key] = &Rec{Tbl: this, Vals: valMap}
-			keys = append(keys, key) // Bolt returns keys in sorted order
-		}
-		this.OrderBy["byKey"] = keys
-		return nil
-	})
-	this.EndWrite()
-	return len(this.RecMap)
+	count, _ := this.load(selection{kind: "all"}, false)
+	return count
 }
 
 // Load1 loads a single record with matching key
 func (this *Table) Load1(key string) int {
-	this.StartWrite()
-	this.RecMap = make(map[string]*Rec)
-	this.OrderBy = make(map[string][]string)
-	db.View(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, this.BktPath)
-		v := bkt.Get(bs(key))
-		if v != nil {
-			valMap := make(ValMap)
-			valMap.fromJson(v)
-			this.RecMap[key] = &Rec{Tbl: this, Vals: valMap}
-		}
-		return nil
-	})
-	this.EndWrite()
-	return len(this.RecMap)
+	count, _ := this.load(selection{kind: "some", keys: []string{key}}, false)
+	return count
 }
 
 // LoadSome loads records where db key matches a key in keys.
 func (this *Table) LoadSome(keys []string) int {
-	this.StartWrite()
-	this.RecMap = make(map[string]*Rec)
-	this.OrderBy = make(map[string][]string)
-	db.View(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, this.BktPath)
-		for _, key := range keys {
-			v := bkt.Get(bs(key))
-			if v == nil {
-				continue
-			}
-			valMap := make(ValMap)
-			valMap.fromJson(v)
-			this.RecMap[key] = &Rec{Tbl: this, Vals: valMap}
-		}
-		return nil
-	})
-	this.EndWrite()
-	return len(this.RecMap)
+	count, _ := this.load(selection{kind: "some", keys: keys}, false)
+	return count
 }
 
-// LoadRange loads db records where key is in a range, from start to end.
+// LoadRange loads db records where key is in a range, from start to end (inclusive).
+// Empty end loads through the last rec in bucket.
 func (this *Table) LoadRange(start, end string) int {
-	this.StartWrite()
-	this.RecMap = make(map[string]*Rec)
-	this.OrderBy = make(map[string][]string)
-	keys := make([]string, 0, 100)
-	db.View(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, this.BktPath)
-		cursor := bkt.Cursor()
-		stop := bs(end)
-		for k, v := cursor.Seek(bs(start)); k != nil && bytes.Compare(k, stop) <= 0; k, v = cursor.Next() {
-			valMap := make(ValMap)
-			valMap.fromJson(v)
-			key := string(k)
-			this.RecMap[key] = &Rec{Tbl: this, Vals: valMap}
-			keys = append(keys, key)
-		}
-		this.OrderBy["byKey"] = keys
-		return nil
-	})
-	this.EndWrite()
-	return len(this.RecMap)
+	count, _ := this.load(selection{kind: "range", bounds: ScanOpts{Start: start, End: end}}, false)
+	return count
 }
 
 // LoadPrefix loads db records where key begins with prefix.
 func (this *Table) LoadPrefix(prefix string) int {
-	this.StartWrite()
-	this.RecMap = make(map[string]*Rec)
-	this.OrderBy = make(map[string][]string)
-	keys := make([]string, 0, 100)
-	db.View(func(tx *bolt.Tx) error {
-		bkt := OpenBucket(tx, this.BktPath)
-		cursor := bkt.Cursor()
-		keyPrefix := bs(prefix)
-		for k, v := cursor.Seek(keyPrefix); bytes.HasPrefix(k, keyPrefix); k, v = cursor.Next() {
-			valMap := make(ValMap)
-			valMap.fromJson(v)
-			key := string(k)
-			this.RecMap[key] = &Rec{Tbl: this, Vals: valMap}
-			keys = append(keys, key)
-		}
-		this.OrderBy["byKey"] = keys
-		return nil
-	})
-	this.EndWrite()
-	return len(this.RecMap)
+	count, _ := this.load(selection{kind: "prefix", bounds: ScanOpts{Prefix: prefix}}, false)
+	return count
 }
 
 // Loop reads thru RecMap calling fn for each record.
@@ -283,46 +256,318 @@ func (this *Table) Loop(fn func(key string, rec *Rec), orderBy ...string) {
 	}
 }
 
+// loopKeys calls fn for each rec in keys, skipping deleted recs and keys not in RecMap.
+func (this *Table) loopKeys(fn func(key string, rec *Rec), keys []string) {
+	for _, key := range keys {
+		rec, found := this.RecMap[key]
+		if !found || rec.Vals["#delete"] == "1" {
+			continue
+		}
+		fn(key, rec)
+	}
+}
+
 // Save writes added/changed/deleted recs in table.RecMap to database.
 // Bolt transaction must be provided (use StartDBWrite to get one).
 // Returns number of records saved.
+// If a relation is violated (see Relation), the transaction is rolled back and Save panics,
+// RecMap is unchanged. Use TrySave to get an error instead.
 func (this *Table) Save(tx *bolt.Tx) int {
-	this.StartWrite()
-	var count int
-	var err error
-	bkt := OpenBucket(tx, this.BktPath)
+	count, err := this.TrySave(tx)
+	if err != nil {
+		tx.Rollback()
+		log.Panic("Save failed, ", err)
+	}
+	return count
+}
+
+// TrySave is the same as Save, except a relation violation is returned as an error.
+// Relations are checked before anything is written, so if error, the db (and tx) and RecMap
+// are unchanged and tx can still be used.
+func (this *Table) TrySave(tx *bolt.Tx) (int, error) {
+	return saveTables(tx, this)
+}
+
+// tableWrites holds the changes of 1 Table found by the 1st pass of saveTables.
+type tableWrites struct {
+	tbl     *Table
+	bkt     *bolt.Bucket
+	deleted []string
+	puts    []putOp
+}
+type putOp struct {
+	key  string
+	rec  *Rec
+	vals ValMap // vals written to db
+}
+
+// pendingWrites returns the added/changed/deleted recs in RecMap (table must be locked).
+func (this *Table) pendingWrites(tx *bolt.Tx) *tableWrites {
+	w := &tableWrites{tbl: this, bkt: this.openBucket(tx), deleted: make([]string, 0), puts: make([]putOp, 0)}
 	for key, rec := range this.RecMap {
-		deleteFlag, _ := rec.Vals["#delete"] // #delete is fldname for delete flag
-		if deleteFlag == "1" {
-			err = bkt.Delete(bs(key))
-			if err != nil { // if key does not exist, not error
+		if rec.Vals["#delete"] == "1" { // #delete is fldname for delete flag
+			w.deleted = append(w.deleted, key)
+			continue
+		}
+		if rec.Vals["#c"] != "1" { // #c is key for change flag field
+			continue
+		}
+		vals := make(ValMap, len(rec.Vals))
+		for fld, val := range rec.Vals {
+			if fld != "#c" {
+				vals[fld] = val
+			}
+		}
+		if rec.chg != nil && (this.MergeSave || rec.chg.partial) {
+			vals = this.mergeVals(w.bkt, key, vals, rec.ChangedFields())
+		}
+		w.puts = append(w.puts, putOp{key, rec, vals})
+	}
+	return w
+}
+
+// saveTables saves tables in tx, with 1 relation plan for all of them, so recs deleted or
+// added by one table are seen by the relation checks of the others (see Detail.Save).
+// If a relation is violated, error is returned before anything is written.
+func saveTables(tx *bolt.Tx, tables ...*Table) (int, error) {
+	// --- 1st pass: find changes, check relations ---
+	writes := make([]*tableWrites, len(tables))
+	for i, tbl := range tables {
+		tbl.StartWrite()
+		defer tbl.EndWrite()
+		writes[i] = tbl.pendingWrites(tx)
+	}
+	plan := newRefPlan()
+	skipPaths := make([][]string, len(tables))
+	for i, w := range writes {
+		skipPaths[i] = w.tbl.BktPath
+		plan.addDeletes(w.tbl.BktPath, w.deleted)
+		for _, op := range w.puts {
+			plan.addPut(w.tbl.BktPath, op.key)
+		}
+	}
+	for _, w := range writes {
+		if err := planRefDeletes(tx, plan, w.tbl.BktPath, w.deleted); err != nil {
+			return 0, err
+		}
+	}
+	for _, w := range writes {
+		for _, op := range w.puts {
+			if err := checkRefs(tx, plan, w.tbl.BktPath, op.key, op.vals); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	// --- 2nd pass: write to db ---
+	if err := plan.apply(tx, skipPaths...); err != nil {
+		tx.Rollback()
+		log.Panic("Save relation changes failed, ", err)
+	}
+	for _, w := range writes {
+		for _, key := range w.deleted {
+			if err := w.bkt.Delete(bs(key)); err != nil { // if key does not exist, not error
 				tx.Rollback()
-				log.Panic("bolt bkt.Delete failed, ", err, ", key:", key, ", bkt:", this.BktPath)
+				log.Panic("bolt bkt.Delete failed, ", err, ", key:", key, ", bkt:", w.tbl.BktPath)
 			}
-			delete(this.RecMap, key) // remove this record from table RecMap
-			count++
-			continue
 		}
-		changed, _ := rec.Vals["#c"] // #c is key for change flag field
-		if changed == "1" {
-			delete(rec.Vals, "#c") // remove change field
-			val := rec.Vals.toJson()
-			err = bkt.Put(bs(key), val)
-			if err != nil {
+		for _, op := range w.puts {
+			if err := w.bkt.Put(bs(op.key), op.vals.toJson()); err != nil {
 				tx.Rollback()
-				log.Panic("bolt bkt.Put failed, ", err, ", key:", key, ", bkt:", this.BktPath)
+				log.Panic("bolt bkt.Put failed, ", err, ", key:", op.key, ", bkt:", w.tbl.BktPath)
+			}
+		}
+	}
+
+	// --- 3rd pass: update RecMap ---
+	var count int
+	for _, w := range writes {
+		for _, key := range w.deleted {
+			delete(w.tbl.RecMap, key) // remove this record from table RecMap
+		}
+		for _, op := range w.puts {
+			rec := op.rec
+			delete(rec.Vals, "#c") // remove change field
+			if rec.chg != nil && w.tbl.MergeSave && !rec.chg.partial {
+				w.tbl.liveChange(op.key, *rec, "", func() {
+					rec.replaceVals(op.vals)
+				})
+			}
+			rec.clearChanges()
+		}
+		count += len(w.deleted) + len(w.puts)
+	}
+	return count, nil
+}
+
+// mergeVals applies changed flds in vals to the current db version of rec with matching key.
+// Returns a new ValMap containing the merged values (a copy of vals if key is not in the db).
+func (this *Table) mergeVals(bkt *bolt.Bucket, key string, vals ValMap, changed []string) ValMap {
+	merged := make(ValMap)
+	v := bkt.Get(bs(key))
+	if v == nil {
+		for fld, val := range vals {
+			if !strings.HasPrefix(fld, "#") {
+				merged[fld] = val
+			}
+		}
+		return merged
+	}
+	merged.fromJson(v)
+	for _, fld := range changed {
+		if val, found := vals[fld]; found {
+			merged[fld] = val
+		} else {
+			delete(merged, fld)
+		}
+	}
+	return merged
+}
+
+// saveOp is a pending write of 1 rec, used by SaveBatched.
+type saveOp struct {
+	key     string
+	del     bool
+	sent    ValMap   // copy of rec vals when op was created
+	vals    ValMap   // vals written to db (merged if MergeSave or partial)
+	changed []string // changed flds, used if merging
+	partial bool     // rec was partially loaded
+}
+
+// pendingSaves returns a saveOp for every added/changed/deleted rec in RecMap.
+// Vals are copied so the ops can be written without holding table lock.
+func (this *Table) pendingSaves() []*saveOp {
+	ops := make([]*saveOp, 0)
+	for key, rec := range this.RecMap {
+		if rec.Vals["#delete"] == "1" {
+			ops = append(ops, &saveOp{key: key, del: true})
+			continue
+		}
+		if rec.Vals["#c"] != "1" {
+			continue
+		}
+		op := &saveOp{key: key, sent: make(ValMap, len(rec.Vals))}
+		for fld, val := range rec.Vals {
+			if fld != "#c" {
+				op.sent[fld] = val
+			}
+		}
+		if rec.chg != nil {
+			op.changed = rec.ChangedFields()
+			op.partial = rec.chg.partial
+		} else {
+			op.changed = make([]string, 0, len(op.sent))
+			for fld := range op.sent {
+				op.changed = append(op.changed, fld)
+			}
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// prepareOp sets op.vals (merged if MergeSave or partial) and checks its relations.
+// It can be called more than once for the same op.
+func (this *Table) prepareOp(tx *bolt.Tx, bkt *bolt.Bucket, plan *refPlan, op *saveOp) error {
+	if op.del {
+		return nil
+	}
+	if this.MergeSave || op.partial {
+		op.vals = this.mergeVals(bkt, op.key, op.sent, op.changed)
+	} else {
+		op.vals = op.sent
+	}
+	return checkRefs(tx, plan, this.BktPath, op.key, op.vals)
+}
+
+// writeOp writes op (after prepareOp) to bkt.
+func (this *Table) writeOp(bkt *bolt.Bucket, op *saveOp) error {
+	if op.del {
+		return bkt.Delete(bs(op.key))
+	}
+	return bkt.Put(bs(op.key), op.vals.toJson())
+}
+
+// opSaved updates RecMap after op has been committed.
+// If rec was changed after op was created, it remains marked as changed.
+func (this *Table) opSaved(op *saveOp) {
+	rec, found := this.RecMap[op.key]
+	if !found {
+		return
+	}
+	if op.del {
+		delete(this.RecMap, op.key)
+		return
+	}
+	if rec.Vals["#delete"] == "1" || !valsEqual(rec.Vals, op.sent) {
+		return
+	}
+	if op.partial {
+		delete(rec.Vals, "#c")
+		rec.clearChanges()
+	} else {
+		this.liveChange(op.key, *rec, "", func() {
+			rec.replaceVals(op.vals)
+		})
+	}
+}
+
+// SaveBatched writes added/changed/deleted recs in table.RecMap to database, like Save.
+// Instead of using an app provided transaction, bolt's DB.Batch is used, so SaveBatched calls
+// made by many goroutines at about the same time share a single write transaction.
+// Cannot be called inside a StartDBWrite/CommitDBWrite process.
+// Returns number of records saved and error. If error, RecMap is unchanged.
+func (this *Table) SaveBatched() (int, error) {
+	this.StartWrite()
+	ops := this.pendingSaves()
+	this.EndWrite()
+	if len(ops) == 0 {
+		return 0, nil
+	}
+	err := Batch(func(tx *bolt.Tx) error {
+		bkt := this.openBucket(tx)
+		deleted := make([]string, 0)
+		for _, op := range ops {
+			if op.del {
+				deleted = append(deleted, op.key)
+			}
+		}
+		plan := newRefPlan()
+		if err := planDeletes(tx, plan, this.BktPath, deleted); err != nil {
+			return err
+		}
+		for _, op := range ops {
+			if err := this.prepareOp(tx, bkt, plan, op); err != nil {
+				return fmt.Errorf("SaveBatched failed, %v, key: %s, bkt: %v", err, op.key, this.BktPath)
+			}
+		}
+		if err := plan.apply(tx, this.BktPath); err != nil {
+			return err
+		}
+		for _, op := range ops {
+			if err := this.writeOp(bkt, op); err != nil {
+				return fmt.Errorf("SaveBatched failed, %v, key: %s, bkt: %v", err, op.key, this.BktPath)
 			}
-			count++
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	this.StartWrite()
+	for _, op := range ops {
+		this.opSaved(op)
 	}
 	this.EndWrite()
-	return count
+	return len(ops), nil
 }
 
 // CreateRecMap creates new RecMap and OrderBy maps.
 func (this *Table) CreateRecMap() {
 	this.RecMap = make(map[string]*Rec)
 	this.OrderBy = make(map[string][]string)
+	this.sels = nil
+	this.rebuildLiveOrders()
 }
 
 // SetBktPath sets BktPath attribute.
@@ -331,6 +576,20 @@ func (this *Table) SetBktPath(bktPath ...string) {
 	this.BktPath = bktPath
 }
 
+// openBucket returns the Table's bucket.
+// Panics if the Table belongs to a Namespace and BktPath is outside of it.
+func (this *Table) openBucket(tx *bolt.Tx) *bolt.Bucket {
+	this.checkNamespace()
+	return OpenBucket(tx, this.BktPath)
+}
+
+// checkNamespace panics if the Table belongs to a Namespace and BktPath is outside of it.
+func (this *Table) checkNamespace() {
+	if this.nsRoot != nil && (len(this.BktPath) <= len(this.nsRoot) || !samePath(this.nsRoot, this.BktPath[:len(this.nsRoot)])) {
+		log.Panic("BktPath ", this.BktPath, " is outside namespace ", this.nsRoot)
+	}
+}
+
 // SetKeySize sets the number of digits in value returned by GetNextKey method.
 func (this *Table) SetKeySize(size int) {
 	this.KeySize = fmt.Sprintf("%s%dd", "%0", size) // size = 5, returns "%05d"
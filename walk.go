@@ -0,0 +1,149 @@
+package bo
+
+import (
+	"github.com/boltdb/bolt"
+	"sort"
+)
+
+// Walk visits bucket bktPath (all root buckets if bktPath is empty), its recs and nested buckets.
+// Fn is called for each bucket with key "" and val nil, then for each rec in the bucket
+// (bktPath is the bucket containing the rec), nested buckets are visited after their parent's recs.
+// Val is only valid during the call. Walk stops if fn returns false.
+// Metadata buckets (see MetaBktName) are skipped unless bktPath is one.
+func Walk(bktPath []string, fn func(bktPath []string, key string, val []byte) bool) {
+	db.View(func(tx *bolt.Tx) error {
+		walkRoot(tx, bktPath, func(path []string, bkt *bolt.Bucket) bool {
+			return walkBucket(bkt, path, fn)
+		})
+		return nil
+	})
+}
+
+// walkRoot calls fn for bucket bktPath or for each root bucket if bktPath is empty.
+func walkRoot(tx *bolt.Tx, bktPath []string, fn func(path []string, bkt *bolt.Bucket) bool) {
+	if len(bktPath) > 0 {
+		fn(append([]string(nil), bktPath...), OpenBucket(tx, bktPath))
+		return
+	}
+	names := make([]string, 0)
+	tx.ForEach(func(name []byte, bkt *bolt.Bucket) error {
+		if !isMetaBkt(name) {
+			names = append(names, string(name))
+		}
+		return nil
+	})
+	for _, name := range names {
+		if !fn([]string{name}, tx.Bucket(bs(name))) {
+			return
+		}
+	}
+}
+
+// walkBucket visits bkt at path, then its recs, then its nested buckets. Returns false if stopped.
+func walkBucket(bkt *bolt.Bucket, path []string, fn func(bktPath []string, key string, val []byte) bool) bool {
+	if !fn(path, "", nil) {
+		return false
+	}
+	nested := make([]string, 0)
+	cursor := bkt.Cursor()
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		if v == nil {
+			if !isMetaBkt(k) {
+				nested = append(nested, string(k))
+			}
+			continue
+		}
+		if !fn(path, string(k), v) {
+			return false
+		}
+	}
+	for _, name := range nested {
+		child := append(append([]string(nil), path...), name)
+		if !walkBucket(bkt.Bucket(bs(name)), child, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// SizeStats summarizes key or value sizes (bytes) of recs in a bucket.
+type SizeStats struct {
+	Min, Max, Total int
+	Mean            float64
+	P50, P90, P99   int // percentiles
+}
+
+// BktStats describes 1 bucket, see Stats.
+type BktStats struct {
+	BktPath []string
+	Depth   int // 0 = bucket passed to Stats, 1 = nested in it, etc.
+	Recs    int
+	Buckets int // nested buckets (not including their nested buckets)
+	KeySize SizeStats
+	ValSize SizeStats
+	Bolt    bolt.BucketStats // page usage, includes nested buckets
+	Schema  FldMap           // nil if not registered (see RegisterSchema)
+}
+
+// Stats returns statistics of bucket bktPath (all root buckets if empty) and every bucket
+// nested inside, in Walk order. Metadata buckets are skipped, as in Walk.
+func Stats(bktPath ...string) []BktStats {
+	list := make([]BktStats, 0)
+	db.View(func(tx *bolt.Tx) error {
+		walkRoot(tx, bktPath, func(path []string, bkt *bolt.Bucket) bool {
+			list = bucketStats(tx, bkt, path, len(path), list)
+			return true
+		})
+		return nil
+	})
+	return list
+}
+
+// bucketStats appends stats of bkt and its nested buckets to list.
+func bucketStats(tx *bolt.Tx, bkt *bolt.Bucket, path []string, baseDepth int, list []BktStats) []BktStats {
+	stats := BktStats{BktPath: path, Depth: len(path) - baseDepth, Bolt: bkt.Stats(), Schema: txSchema(tx, path)}
+	keySizes := make([]int, 0)
+	valSizes := make([]int, 0)
+	nested := make([]string, 0)
+	bkt.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if !isMetaBkt(k) {
+				nested = append(nested, string(k))
+			}
+			return nil
+		}
+		keySizes = append(keySizes, len(k))
+		valSizes = append(valSizes, len(v))
+		return nil
+	})
+	stats.Recs = len(keySizes)
+	stats.Buckets = len(nested)
+	stats.KeySize = sizeStats(keySizes)
+	stats.ValSize = sizeStats(valSizes)
+	list = append(list, stats)
+	for _, name := range nested {
+		child := append(append([]string(nil), path...), name)
+		list = bucketStats(tx, bkt.Bucket(bs(name)), child, baseDepth, list)
+	}
+	return list
+}
+
+// sizeStats returns summary of sizes (sizes is sorted).
+func sizeStats(sizes []int) SizeStats {
+	var stats SizeStats
+	if len(sizes) == 0 {
+		return stats
+	}
+	sort.Ints(sizes)
+	for _, size := range sizes {
+		stats.Total += size
+	}
+	pct := func(p int) int {
+		return sizes[(len(sizes)-1)*p/100]
+	}
+	stats.Min = sizes[0]
+	stats.Max = sizes[len(sizes)-1]
+	stats.Mean = float64(stats.Total) / float64(len(sizes))
+	stats.P50, stats.P90, stats.P99 = pct(50), pct(90), pct(99)
+	return stats
+}
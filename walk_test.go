@@ -0,0 +1,68 @@
+// These tests check Walk, Stats and schemas, using "garden" buckets.
+
+package bo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWalkStats(t *testing.T) {
+	CreateBucketIfNotExists("garden", "beds", "north")
+	CreateBucketIfNotExists("garden", "tools")
+	plants := NewTable(FldMap{"name": "str", "qty": "int"}, NotShared, "garden", "beds")
+	plants.CreateRecMap()
+	plants.AddRec("p1", ValMap{"name": "rose", "qty": "3"})
+	plants.AddRec("p2", ValMap{"name": "fern"})
+	plants.AddRec("p3", ValMap{"name": "lily", "qty": "12"})
+	tx := StartDBWrite()
+	plants.Save(tx)
+	CommitDBWrite(tx)
+	plants.RegisterSchema()
+	if GetSchema("garden", "beds")["qty"] != "int" || GetSchema("garden") != nil {
+		t.Fatal("GetSchema wrong")
+	}
+
+	visited := make([]string, 0)
+	Walk([]string{"garden"}, func(bktPath []string, key string, val []byte) bool {
+		visited = append(visited, strings.Join(bktPath, "/")+":"+key)
+		return true
+	})
+	want := "garden: garden/beds: garden/beds:p1 garden/beds:p2 garden/beds:p3 garden/beds/north: garden/tools:"
+	if strings.Join(visited, " ") != want {
+		t.Fatal("Walk order wrong: ", visited)
+	}
+	var count int
+	Walk([]string{"garden"}, func(bktPath []string, key string, val []byte) bool {
+		count++
+		return count < 3
+	})
+	if count != 3 {
+		t.Fatal("Walk did not stop: ", count)
+	}
+
+	stats := Stats("garden")
+	if len(stats) != 4 || stats[1].Depth != 1 || stats[2].Depth != 2 {
+		t.Fatal("Stats buckets wrong: ", len(stats))
+	}
+	beds := stats[1]
+	if beds.Recs != 3 || beds.Buckets != 1 || beds.Schema["name"] != "str" || stats[0].Schema != nil {
+		t.Fatal("Stats counts or schema wrong: ", beds.Recs, beds.Buckets, beds.Schema)
+	}
+	if beds.KeySize.Min != 2 || beds.KeySize.Total != 6 || beds.ValSize.Max <= beds.ValSize.Min {
+		t.Fatal("Stats sizes wrong: ", beds.KeySize, beds.ValSize)
+	}
+	if beds.Bolt.KeyN != 4 || beds.Bolt.BucketN != 2 {
+		t.Fatal("bolt stats wrong: ", beds.Bolt.KeyN, beds.Bolt.BucketN)
+	}
+	for _, stats := range Stats() { // metadata bucket holds the registered schema
+		if stats.BktPath[0] == MetaBktName {
+			t.Fatal("Stats includes metadata bucket")
+		}
+	}
+	for _, info := range ListBuckets() {
+		if info.Name == MetaBktName {
+			t.Fatal("ListBuckets includes metadata bucket")
+		}
+	}
+}